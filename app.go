@@ -2,12 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"t-log/internal/attachment"
 	"t-log/internal/command"
 	"t-log/internal/config"
+	"t-log/internal/draft"
+	"t-log/internal/fsutil"
 	hk "t-log/internal/hotkey"
 	"t-log/internal/note"
+	"t-log/internal/plugin"
+	"t-log/internal/queue"
 
 	"time"
 
@@ -18,96 +29,332 @@ import (
 // App struct
 type App struct {
 	ctx         context.Context
-	config      *config.AppConfig
-	hk          *hotkey.Hotkey
+	cancel      context.CancelFunc
+	configSvc   *config.Service
 	cmdRegistry *command.CommandRegistry
 	attachMgr   *attachment.Manager
+	userCmds    commandSet
+	plugins     *plugin.Manager
+	pluginCmds  commandSet
+	undo        undoStack    // Notes saved this session, for UndoLastNote
+	queue       *queue.Queue // Notes waiting for unavailable storage (nil if the queue can't be used)
+	drafts      *draft.Store // Unsaved editor text
+	args        cliArgs
+
+	mu             sync.Mutex
+	hk             *hotkey.Hotkey                   // Main capture hotkey
+	notebookHks    []*hotkey.Hotkey                 // Per-notebook capture hotkeys
+	target         string                           // Notebook the capture window was opened for ("" = active)
+	stopNotesWatch context.CancelFunc               // Stops the watchers started by watchNotes
+	indexes        map[notebookKey]*note.DateIndex  // Per-notebook scans of the daily files, see GetCalendar
+	stats          map[notebookKey]*note.StatsCache // Per-notebook statistics, see GetStats
 }
 
 // NewApp creates a new App application struct
-func NewApp() *App {
+func NewApp(configSvc *config.Service) *App {
 	return &App{
+		configSvc:   configSvc,
 		cmdRegistry: command.NewRegistry(),
 	}
 }
 
+// cfg returns the current configuration; it may change between calls
+func (a *App) cfg() *config.AppConfig {
+	return a.configSvc.Get()
+}
+
+// active returns the notebook that history, search and "open" commands use
+func (a *App) active() (string, note.Layout) {
+	nb := a.cfg().Active()
+	return nb.RootPath, note.Layout(nb.Layout)
+}
+
+// notebookPrefixRegex matches "@name " at the start of a captured note
+var notebookPrefixRegex = regexp.MustCompile(`^@([A-Za-z0-9_-]+)\s+`)
+
+// captureNotebook picks the notebook a note is saved to: an @name prefix naming
+// a notebook wins (and is stripped), then the notebook whose hotkey opened the
+// window, then the active notebook. An @word that is not a notebook is kept
+// as part of the note.
+func (a *App) captureNotebook(content string) (config.Notebook, string) {
+	cfg := a.cfg()
+
+	if m := notebookPrefixRegex.FindStringSubmatch(content); m != nil {
+		if nb, ok := cfg.FindNotebook(m[1]); ok {
+			return nb, content[len(m[0]):]
+		}
+	}
+
+	a.mu.Lock()
+	target := a.target
+	a.mu.Unlock()
+	if nb, ok := cfg.FindNotebook(target); ok && target != "" {
+		return nb, content
+	}
+	return cfg.Active(), content
+}
+
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
-		a.config = config.DefaultConfig()
-	} else {
-		a.config = cfg
+	// Initialize managers
+	a.attachMgr = attachment.NewManager(a.cfg())
+	a.startDrafts()
+
+	// Register global hotkeys
+	a.registerHotkeys(a.cfg())
+
+	// Every subsystem follows config changes, whether from Settings or from
+	// config.json being edited on disk
+	a.configSvc.Subscribe(a.onConfigChanged)
+	watchCtx, cancel := context.WithCancel(ctx)
+	a.cancel = cancel
+	if err := a.configSvc.Watch(watchCtx); err != nil {
+		fmt.Printf("Error watching config: %v\n", err)
 	}
 
-	// Initialize managers
-	a.attachMgr = attachment.NewManager(a.config)
+	// Files dropped onto the window become attachments
+	runtime.OnFileDrop(ctx, func(x, y int, paths []string) {
+		a.attachAndNotify(paths)
+	})
+
+	// Register Commands. Usage history lives next to the config file so
+	// separate --config profiles rank independently.
+	a.cmdRegistry.SetFrecency(command.LoadFrecency(filepath.Join(a.configDir(), "command-usage.json")))
+	a.registerCommands()
+
+	// Commands from commands.json / commands.yaml, reloaded when the file changes
+	a.loadUserCommands()
+	if err := fsutil.WatchFiles(watchCtx, a.configDir(), command.UserCommandFiles, 300*time.Millisecond, a.loadUserCommands); err != nil {
+		fmt.Printf("Error watching user commands: %v\n", err)
+	}
+
+	// Plugins: executables in the plugins folder, reloaded when one of them changes
+	a.plugins = plugin.NewManager(filepath.Join(a.configDir(), plugin.DirName), filepath.Join(a.configDir(), plugin.DataDirName))
+	if err := os.MkdirAll(a.plugins.Dir(), 0755); err != nil {
+		fmt.Printf("Error creating plugins directory: %v\n", err)
+	}
+	go a.loadPlugins() // Describing every plugin may take up to its timeout
+	if err := fsutil.WatchMatching(watchCtx, a.plugins.Dir(), time.Second, a.plugins.Watches, a.loadPlugins); err != nil {
+		fmt.Printf("Error watching plugins: %v\n", err)
+	}
+
+	// Notes that could not be written (offline network drive...) wait here
+	a.startQueue(watchCtx)
+
+	// Daily files edited outside the app refresh the history panel
+	a.watchNotes(a.cfg())
+}
+
+// onConfigChanged pushes a new configuration to every subsystem
+func (a *App) onConfigChanged(old, cfg *config.AppConfig) {
+	a.attachMgr.SetConfig(cfg)
+
+	if hotkeysChanged(old, cfg) {
+		a.replaceHotkeys(cfg)
+	}
+
+	if notebookRootsChanged(old, cfg) {
+		a.watchNotes(cfg)
+	}
+
+	runtime.EventsEmit(a.ctx, "config:changed", cfg)
+}
+
+// hotkeysChanged reports whether any notebook's hotkey was added, removed or changed
+func hotkeysChanged(old, cfg *config.AppConfig) bool {
+	oldNbs, newNbs := old.AllNotebooks(), cfg.AllNotebooks()
+	if len(oldNbs) != len(newNbs) {
+		return true
+	}
+	for i := range oldNbs {
+		if oldNbs[i].Name != newNbs[i].Name || oldNbs[i].Hotkey != newNbs[i].Hotkey {
+			return true
+		}
+	}
+	return false
+}
+
+// registerHotkeys registers the main hotkey and the hotkey of every notebook that has one
+func (a *App) registerHotkeys(cfg *config.AppConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.registerHotkeysLocked(cfg)
+}
+
+// unregisterHotkeys releases every hotkey registered by registerHotkeys
+func (a *App) unregisterHotkeys() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.unregisterHotkeysLocked()
+}
+
+// replaceHotkeys swaps the registered hotkeys for those of cfg in one step, so
+// a config change and shutdown can't interleave (config changes arrive on the
+// watcher's goroutine)
+func (a *App) replaceHotkeys(cfg *config.AppConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.unregisterHotkeysLocked()
+	a.registerHotkeysLocked(cfg)
+}
+
+// registerHotkeysLocked is registerHotkeys for callers holding a.mu. The key
+// listeners take a.mu themselves, but hotkey events are queued, so holding it
+// here can't block them from being delivered later.
+func (a *App) registerHotkeysLocked(cfg *config.AppConfig) {
+	a.hk = a.registerHotkey(cfg.Hotkey, "")
 
-	// Register global hotkey
-	mods, key, err := hk.ParseHotkey(a.config.Hotkey)
+	for _, nb := range cfg.Notebooks {
+		if nb.Hotkey == "" {
+			continue
+		}
+		if h := a.registerHotkey(nb.Hotkey, nb.Name); h != nil {
+			a.notebookHks = append(a.notebookHks, h)
+		}
+	}
+}
+
+// unregisterHotkeysLocked is unregisterHotkeys for callers holding a.mu
+func (a *App) unregisterHotkeysLocked() {
+	if a.hk != nil {
+		a.hk.Unregister()
+		a.hk = nil
+	}
+	for _, h := range a.notebookHks {
+		h.Unregister()
+	}
+	a.notebookHks = nil
+}
+
+// registerHotkey registers a global hotkey that shows the capture window for
+// notebook ("" = the active one). An invalid main hotkey falls back to the
+// default; an invalid notebook hotkey is skipped. Returns nil when the
+// hotkey could not be registered (taken by another program).
+func (a *App) registerHotkey(spec, notebook string) *hotkey.Hotkey {
+	mods, key, err := hk.ParseHotkey(spec)
 	if err != nil {
-		fmt.Printf("Error parsing hotkey '%s': %v. Using default Alt+Space.\n", a.config.Hotkey, err)
+		if notebook != "" {
+			fmt.Printf("Error parsing hotkey '%s' for notebook %s: %v\n", spec, notebook, err)
+			return nil
+		}
+		fmt.Printf("Error parsing hotkey '%s': %v. Using default Alt+Space.\n", spec, err)
 		mods = []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModAlt}
 		key = hotkey.KeySpace
 	}
 
-	a.hk = hotkey.New(mods, key)
-	if err := a.hk.Register(); err != nil {
+	h := hotkey.New(mods, key)
+	if err := h.Register(); err != nil {
+		// Unregister fails on it and never closes Keydown, so a listener
+		// started now would leak on every reload
 		fmt.Printf("Failed to register hotkey: %v\n", err)
+		return nil
 	}
 
-	// Start listening for hotkey events in a goroutine
+	// Start listening for hotkey events in a goroutine.
+	// Unregister closes the channel, which ends the loop.
 	go func() {
-		for range a.hk.Keydown() {
-			// On Windows with Acrylic, resizing a hidden window or resizing immediately
-			// after show can crash. The safest way is:
-			// 1. Show the window (it might be wrong size)
-			// 2. Wait a tiny bit (let DWM catch up) - handled by frontend event delay
-			// 3. Emit event for frontend to focus input
-			runtime.WindowShow(a.ctx)
-			// Force restore to ensure it's not minimized
-			if runtime.WindowIsMinimised(a.ctx) {
-				runtime.WindowUnminimise(a.ctx)
-			}
-			// Flash Top: Set AlwaysOnTop to bring to front, then disable it
-			// This allows the user to Alt-Tab away or click other windows later.
-			runtime.WindowSetAlwaysOnTop(a.ctx, true)
-			go func() {
-				time.Sleep(100 * time.Millisecond) // Short delay to ensure it pops up
-				runtime.WindowSetAlwaysOnTop(a.ctx, false)
-			}()
-
-			// Delay event emission slightly to ensure window is fully rendered
-			// This helps with the "flash crash" on some Windows systems
-			go func() {
-				// Short sleep (e.g. 50ms) could be done here if needed,
-				// but frontend timeout is usually enough.
-				// Let's keep it immediate here but rely on frontend delay.
-				runtime.EventsEmit(a.ctx, "app:reset")
-			}()
+		for range h.Keydown() {
+			a.showCaptureWindow(notebook)
 		}
 	}()
 
-	// Register Commands
-	a.registerCommands()
+	return h
+}
+
+// showCaptureWindow brings the window to the front and resets the input.
+// Notes saved before the window is hidden go to notebook ("" = active).
+func (a *App) showCaptureWindow(notebook string) {
+	a.mu.Lock()
+	a.target = notebook
+	a.mu.Unlock()
+	a.attachMgr.SetNotebook(notebook)
+
+	// On Windows with Acrylic, resizing a hidden window or resizing immediately
+	// after show can crash. The safest way is:
+	// 1. Show the window (it might be wrong size)
+	// 2. Wait a tiny bit (let DWM catch up) - handled by frontend event delay
+	// 3. Emit event for frontend to focus input
+	runtime.WindowShow(a.ctx)
+	// Force restore to ensure it's not minimized
+	if runtime.WindowIsMinimised(a.ctx) {
+		runtime.WindowUnminimise(a.ctx)
+	}
+	// Flash Top: Set AlwaysOnTop to bring to front, then disable it
+	// This allows the user to Alt-Tab away or click other windows later.
+	runtime.WindowSetAlwaysOnTop(a.ctx, true)
+	go func() {
+		time.Sleep(100 * time.Millisecond) // Short delay to ensure it pops up
+		runtime.WindowSetAlwaysOnTop(a.ctx, false)
+	}()
+
+	// Delay event emission slightly to ensure window is fully rendered
+	// This helps with the "flash crash" on some Windows systems
+	go func() {
+		// Short sleep (e.g. 50ms) could be done here if needed,
+		// but frontend timeout is usually enough.
+		// Let's keep it immediate here but rely on frontend delay.
+		runtime.EventsEmit(a.ctx, "app:reset", a.CaptureNotebook())
+	}()
+}
+
+// domReady is called once the frontend has loaded, so events emitted here are received
+func (a *App) domReady(ctx context.Context) {
+	if len(a.args.Attach) > 0 {
+		a.attachAndNotify(a.args.Attach)
+	}
 }
 
 // registerCommands registers all available commands
 func (a *App) registerCommands() {
+	// Notebook parameters complete to (and must be) a configured notebook
+	a.cmdRegistry.SetTypeProvider(command.ParamNotebook, func(prefix string) []command.Completion {
+		completions := []command.Completion{}
+		for _, nb := range a.cfg().AllNotebooks() {
+			if strings.HasPrefix(nb.Name, prefix) {
+				completions = append(completions, command.Completion{Value: nb.Name, Label: nb.Name, Description: nb.RootPath})
+			}
+		}
+		return completions
+	})
+
+	// History views typed as "/today", "/days 14"... in the capture window
+	a.registerSlashCommands()
+	a.registerDraftCommands()
+	a.registerExportCommands()
+	a.registerReportCommands()
+	a.registerImportCommands()
+	a.registerSiteCommands()
+	a.registerStatsCommands()
+
 	// Open Specific Date
 	a.cmdRegistry.Register(command.Command{
 		ID:          "cmd:open-date",
 		Title:       "Open Date...",
 		Description: "Select a specific date to open",
-	}, func(args []string) error {
-		// This command is handled by frontend to show date picker
-		// But we register it so it appears in the list
-		return nil
+		Usage:       "open-date <YYYY-MM-DD>",
+		Aliases:     []string{"date", "goto", "open"},
+		Params: []command.Param{
+			{Name: "date", Type: command.ParamDate, Prompt: "Select date... (YYYY-MM-DD)", Required: true},
+		},
+	}, func(args command.Args) error {
+		return a.OpenDateNote(args.Get("date"))
+	})
+	a.cmdRegistry.SetParamProvider("cmd:open-date", "date", func(prefix string) []command.Completion {
+		// Suggest days that have notes, newest first
+		dates, err := a.ListNoteDates()
+		if err != nil {
+			return []command.Completion{}
+		}
+		completions := []command.Completion{}
+		for _, d := range dates {
+			if strings.Contains(d, prefix) {
+				completions = append(completions, command.Completion{Value: d, Label: d})
+			}
+		}
+		return completions
 	})
 
 	// Help
@@ -115,20 +362,138 @@ func (a *App) registerCommands() {
 		ID:          "cmd:help",
 		Title:       "Help",
 		Description: "Open documentation in browser",
-	}, func(args []string) error {
+		Aliases:     []string{"docs", "readme"},
+	}, func(args command.Args) error {
 		runtime.BrowserOpenURL(a.ctx, "https://github.com/yourusername/t-log/blob/master/README.md") // Update URL as needed
 		return nil
 	})
 
-	// Find (placeholder for discovery, logic handled in frontend/backend search)
+	// Find: completions are the search results; choosing one opens the note at that line
 	a.cmdRegistry.Register(command.Command{
 		ID:          "cmd:find",
 		Title:       "Find / Search",
 		Description: "Search notes by keyword (Type 'find ')",
 		Usage:       "find <keyword>",
-	}, func(args []string) error {
-		// Backend doesn't need to do anything for 'find' as it switches mode in frontend
-		// But we register it so it shows up in the list
+		Aliases:     []string{"search", "grep"},
+		Params: []command.Param{
+			{Name: "match", Type: command.ParamString, Prompt: "Search notes...", Required: true},
+		},
+	}, func(args command.Args) error {
+		path, line, ok := parseLocation(args.Get("match"))
+		if !ok {
+			return fmt.Errorf("select a search result to open")
+		}
+		return a.OpenNoteAt(path, line)
+	})
+	a.cmdRegistry.SetParamProvider("cmd:find", "match", func(prefix string) []command.Completion {
+		completions := []command.Completion{}
+		if strings.TrimSpace(prefix) == "" {
+			return completions
+		}
+		for _, r := range a.SearchNotes(prefix) {
+			completions = append(completions, command.Completion{
+				Value:       fmt.Sprintf("%s:%d", r.FilePath, r.LineNo),
+				Label:       r.Content,
+				Description: strings.TrimSpace(r.Date + " " + r.Time),
+			})
+		}
+		return completions
+	})
+
+	// Attach a file by path
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:attach",
+		Title:       "Attach File...",
+		Description: "Copy a file into the attachment folder and insert a link",
+		Usage:       "attach <path>",
+		Aliases:     []string{"file", "upload"},
+		Params: []command.Param{
+			{Name: "path", Type: command.ParamFile, Prompt: "File to attach...", Required: true},
+		},
+	}, func(args command.Args) error {
+		a.attachAndNotify([]string{args.Get("path")})
+		return nil
+	})
+
+	// Attachment garbage collection
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:attachment-gc",
+		Title:       "Clean Up Attachments",
+		Description: "Find attachments no note references and move them to trash",
+		Usage:       "attachment-gc [preview|apply]",
+		Aliases:     []string{"cleanup", "gc", "trash"},
+		Params: []command.Param{
+			{Name: "mode", Type: command.ParamEnum, Options: []string{"preview", "apply"}, Default: "preview"},
+		},
+	}, func(args command.Args) error {
+		// A preview is a dry run; the frontend shows the report and re-runs the
+		// command with "apply" once the user confirms.
+		report, err := a.attachMgr.CollectGarbage(args.Get("mode") != "apply")
+		if err != nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "attachment:gc-report", report)
+		return nil
+	})
+
+	// Attachment link conversion
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:convert-links",
+		Title:       "Convert Attachment Links",
+		Description: "Rewrite attachment links in all notes to relative or /attachments/ form",
+		Usage:       "convert-links <relative|absolute> [preview|apply]",
+		Aliases:     []string{"links", "relative", "portable"},
+		Params: []command.Param{
+			{Name: "style", Type: command.ParamEnum, Prompt: "Convert links to...", Required: true,
+				Options: []string{config.LinksRelative, config.LinksAbsolute}},
+			{Name: "mode", Type: command.ParamEnum, Options: []string{"preview", "apply"}, Default: "preview"},
+		},
+	}, func(args command.Args) error {
+		dryRun := args.Get("mode") != "apply"
+		report, err := attachment.ConvertLinks(a.cfg().Active().RootPath, args.Get("style"), dryRun)
+		if err != nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "attachment:convert-report", report)
+		return nil
+	})
+
+	// Notebooks
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:switch-notebook",
+		Title:       "Switch Notebook...",
+		Description: "Choose the notebook used for capture and history",
+		Usage:       "switch-notebook <name>",
+		Aliases:     []string{"notebook", "profile"},
+		Params: []command.Param{
+			{Name: "notebook", Type: command.ParamNotebook, Prompt: "Switch to notebook...", Required: true},
+		},
+	}, func(args command.Args) error {
+		return a.SetActiveNotebook(args.Get("notebook"))
+	})
+
+	// Retry Pending Notes
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:flush-queue",
+		Title:       "Retry Pending Notes",
+		Description: "Write notes saved while their notebook folder was unavailable",
+		Aliases:     []string{"queue", "pending", "sync"},
+	}, func(args command.Args) error {
+		return a.flushQueue()
+	})
+
+	// Undo Last Note
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:undo-note",
+		Title:       "Undo Last Note",
+		Description: "Remove the note saved last and put it back in the editor",
+		Aliases:     []string{"undo", "revert", "unsend"},
+	}, func(args command.Args) error {
+		content, err := a.UndoLastNote()
+		if err != nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "editor:insert", content)
 		return nil
 	})
 
@@ -137,7 +502,8 @@ func (a *App) registerCommands() {
 		ID:          "cmd:settings",
 		Title:       "Settings",
 		Description: "Open configuration settings",
-	}, func(args []string) error {
+		Aliases:     []string{"preferences", "config", "options"},
+	}, func(args command.Args) error {
 		// The frontend CommandPalette will intercept this and handle it,
 		// OR we can emit an event here if executed via backend logic.
 		// Let's emit the event just in case.
@@ -146,11 +512,36 @@ func (a *App) registerCommands() {
 	})
 }
 
+// parseLocation splits a "path:line" search result value. The line number is
+// taken after the last colon so Windows drive letters survive.
+func parseLocation(value string) (string, int, bool) {
+	i := strings.LastIndex(value, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(value[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return value[:i], line, true
+}
+
 // shutdown is called at application termination
 func (a *App) shutdown(ctx context.Context) {
-	if a.hk != nil {
-		a.hk.Unregister()
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.mu.Lock()
+	if a.stopNotesWatch != nil {
+		a.stopNotesWatch()
+	}
+	a.mu.Unlock()
+	if a.drafts != nil {
+		if err := a.drafts.Flush(); err != nil {
+			fmt.Printf("Error saving draft: %v\n", err)
+		}
 	}
+	a.unregisterHotkeys()
 }
 
 // Greet returns a greeting for the given name
@@ -160,17 +551,30 @@ func (a *App) Greet(name string) string {
 
 // GetConfig returns the current configuration
 func (a *App) GetConfig() *config.AppConfig {
-	return a.config
+	return a.cfg()
+}
+
+// GetConfigPath returns the config file in use, so settings can show where it lives
+func (a *App) GetConfigPath() string {
+	return a.configSvc.Path()
+}
+
+// GetConfigSource returns the file the config was loaded from. It differs from
+// GetConfigPath when config.json was corrupt and the backup was used.
+func (a *App) GetConfigSource() string {
+	return a.configSvc.Source()
+}
+
+// ValidateConfig checks a config from the settings UI and returns the
+// field-level errors to show next to each input (empty when valid)
+func (a *App) ValidateConfig(cfg config.AppConfig) []config.FieldError {
+	return cfg.FieldErrors()
 }
 
-// UpdateConfig updates the application configuration
+// UpdateConfig validates and saves the configuration. Subscribers (attachment
+// manager, hotkey, asset handler) pick up the change immediately.
 func (a *App) UpdateConfig(cfg config.AppConfig) error {
-	a.config = &cfg
-	// In a real app, we might want to re-init things if config changes (like hotkey)
-	// For now, we just save it.
-	// Hotkey update would require unregistering old and registering new, which is complex safely.
-	// We'll assume restart required for hotkey for now, as per spec US3 test scenario "Restart".
-	return config.SaveConfig(config.ConfigFileName, a.config)
+	return a.configSvc.Update(cfg)
 }
 
 // SelectRootPath opens a dialog to select the root path
@@ -184,14 +588,94 @@ func (a *App) SelectRootPath() (string, error) {
 	return path, nil
 }
 
-// SaveNote appends a new note to today's markdown file
-func (a *App) SaveNote(content string) error {
-	return note.SaveNote(a.config.RootPath, content)
+// SaveNote appends a new note to today's markdown file.
+// "@name text" saves text to the notebook called name.
+// Plugins with save hooks may transform the note first and are told after.
+// The returned entry can be taken back with UndoLastNote.
+func (a *App) SaveNote(content string) (note.SavedEntry, error) {
+	nb, content := a.captureNotebook(content)
+	if content == "" {
+		return note.SavedEntry{}, nil
+	}
+
+	now := time.Now()
+
+	// Attachments pasted before an @name prefix was typed went to the notebook
+	// the window was opened for; their links must work from this one
+	if relocated, err := a.attachMgr.Relocate(content, nb, now); err != nil {
+		fmt.Printf("Error moving attachments to notebook %s: %v\n", nb.Name, err)
+	} else {
+		content = relocated
+	}
+
+	hookNote := plugin.Note{
+		Content:  content,
+		Notebook: nb.Name,
+		Date:     now.Format("2006-01-02"),
+		Time:     now.Format("15:04"),
+	}
+
+	if a.plugins != nil {
+		var skip bool
+		if hookNote.Content, skip = a.plugins.PreSave(hookNote); skip {
+			return note.SavedEntry{}, nil
+		}
+	}
+
+	// Notes already waiting for this notebook go first
+	if a.queue != nil && a.queue.HasPending(nb.RootPath) {
+		entry, err := a.enqueue(nb, hookNote.Content, now)
+		go a.flushQueue() // The storage may be back already
+		return entry, err
+	}
+
+	entry, err := note.SaveNoteAt(nb.RootPath, note.Layout(nb.Layout), hookNote.Content, now)
+	if err != nil {
+		// A note that may already be in the file would be written twice
+		if a.queue == nil || !errors.Is(err, note.ErrNotWritten) {
+			return note.SavedEntry{}, err
+		}
+		// Keep the thought; it is written once the storage is back
+		fmt.Printf("Error saving note, queued for retry: %v\n", err)
+		return a.enqueue(nb, hookNote.Content, now)
+	}
+	a.undo.push(entry)
+	// The watcher may be down or still debouncing; the calendar must not wait for it
+	a.invalidateNotes(nb.RootPath, []string{hookNote.Date})
+
+	if a.plugins != nil {
+		hookNote.File = entry.File
+		a.plugins.PostSave(hookNote)
+	}
+	return entry, nil
+}
+
+// GetNotebooks returns every notebook, the default one first
+func (a *App) GetNotebooks() []config.Notebook {
+	return a.cfg().AllNotebooks()
+}
+
+// CaptureNotebook returns the name of the notebook new notes go to
+// (without an @name prefix)
+func (a *App) CaptureNotebook() string {
+	nb, _ := a.captureNotebook("")
+	return nb.Name
+}
+
+// SetActiveNotebook switches the notebook used for capture, history and search
+func (a *App) SetActiveNotebook(name string) error {
+	cfg := *a.cfg()
+	if _, ok := cfg.FindNotebook(name); !ok {
+		return fmt.Errorf("unknown notebook: %s", name)
+	}
+	cfg.ActiveNotebook = name
+	return a.configSvc.Update(cfg)
 }
 
 // GetRecentNotes reads and parses notes from the last N days
 func (a *App) GetRecentNotes() []note.DailyNote {
-	entries, err := note.GetRecentNotes(a.config.RootPath, a.config.HistoryDays)
+	rootPath, layout := a.active()
+	entries, err := note.GetRecentNotes(rootPath, layout, a.cfg().HistoryDays)
 	if err != nil {
 		fmt.Printf("Error getting recent notes: %v\n", err)
 		return []note.DailyNote{}
@@ -202,7 +686,8 @@ func (a *App) GetRecentNotes() []note.DailyNote {
 // GetNotesByDateRange reads notes within a start and end date range
 // start, end format: YYYY-MM-DD
 func (a *App) GetNotesByDateRange(start, end string) []note.NoteEntry {
-	entries, err := note.GetNotesByDateRange(a.config.RootPath, start, end)
+	rootPath, layout := a.active()
+	entries, err := note.GetNotesByDateRange(rootPath, layout, start, end)
 	if err != nil {
 		fmt.Printf("Error getting notes by date range: %v\n", err)
 		return []note.NoteEntry{}
@@ -212,7 +697,8 @@ func (a *App) GetNotesByDateRange(start, end string) []note.NoteEntry {
 
 // GetDailyNotes reads full file contents within a start and end date range
 func (a *App) GetDailyNotes(start, end string) []note.DailyNote {
-	notes, err := note.GetDailyNotes(a.config.RootPath, start, end)
+	rootPath, layout := a.active()
+	notes, err := note.GetDailyNotes(rootPath, layout, start, end)
 	if err != nil {
 		fmt.Printf("Error getting daily notes: %v\n", err)
 		return []note.DailyNote{}
@@ -222,12 +708,14 @@ func (a *App) GetDailyNotes(start, end string) []note.DailyNote {
 
 // OpenDailyNote opens the current day's markdown file in the system default editor
 func (a *App) OpenDailyNote() error {
-	return note.OpenDailyNote(a.config.RootPath)
+	rootPath, layout := a.active()
+	return note.OpenDailyNote(rootPath, layout)
 }
 
 // OpenDateNote opens the markdown file for a specific date (YYYY-MM-DD)
 func (a *App) OpenDateNote(dateStr string) error {
-	return note.OpenDateNote(a.config.RootPath, dateStr)
+	rootPath, layout := a.active()
+	return note.OpenDateNote(rootPath, layout, dateStr)
 }
 
 // OpenNoteAt opens a specific note file at a specific line number
@@ -238,6 +726,11 @@ func (a *App) OpenNoteAt(filePath string, lineNo int) error {
 // HideWindow hides the application window
 func (a *App) HideWindow() {
 	runtime.WindowHide(a.ctx)
+	// The next capture goes to the active notebook unless a notebook hotkey opens it
+	a.mu.Lock()
+	a.target = ""
+	a.mu.Unlock()
+	a.attachMgr.SetNotebook("")
 	// Reset AlwaysOnTop when hidden so it doesn't interfere next time or if logic changes
 	runtime.WindowSetAlwaysOnTop(a.ctx, false)
 }
@@ -247,14 +740,38 @@ func (a *App) GetCommands() []command.Command {
 	return a.cmdRegistry.GetCommands()
 }
 
-// ExecuteCommand executes a specific command by ID
+// SearchCommands returns the commands matching query, ranked by match
+// quality and how often and recently each was used
+func (a *App) SearchCommands(query string) []command.Command {
+	return a.cmdRegistry.Search(query)
+}
+
+// ExecuteCommand executes a specific command by ID.
+// args are positional values for the command's parameters.
 func (a *App) ExecuteCommand(id string, args []string) error {
 	return a.cmdRegistry.Execute(id, args)
 }
 
+// ExecuteCommandWithSelection executes a command with the text selected in the
+// capture window, available to user commands as {selection}
+func (a *App) ExecuteCommandWithSelection(id string, args []string, selection string) error {
+	return a.cmdRegistry.ExecuteWith(id, args, map[string]string{"selection": selection})
+}
+
+// CompleteCommandArg returns suggestions for one parameter of a command,
+// given what the user has typed so far
+func (a *App) CompleteCommandArg(id, param, prefix string) []command.Completion {
+	completions, err := a.cmdRegistry.Complete(id, param, prefix)
+	if err != nil {
+		fmt.Printf("Error completing %s %s: %v\n", id, param, err)
+		return []command.Completion{}
+	}
+	return completions
+}
+
 // SearchNotes performs a text search across all notes
 func (a *App) SearchNotes(query string) []note.SearchResult {
-	results, err := note.SearchNotes(a.config.RootPath, query)
+	results, err := note.SearchNotes(a.cfg().Active().RootPath, query)
 	if err != nil {
 		fmt.Printf("Error searching notes: %v\n", err)
 		return []note.SearchResult{}
@@ -267,7 +784,70 @@ func (a *App) UploadAttachment(content []byte, filename string) (string, error)
 	return a.attachMgr.SaveAttachment(content, filename)
 }
 
-// ListNoteDates returns a list of all available note dates
+// ScanOrphanedAttachments lists attachments that no note references (dry run)
+func (a *App) ScanOrphanedAttachments() (*attachment.GCReport, error) {
+	return a.attachMgr.CollectGarbage(true)
+}
+
+// TrashOrphanedAttachments moves unreferenced attachments to the trash folder
+// and purges trash older than the configured retention period
+func (a *App) TrashOrphanedAttachments() (*attachment.GCReport, error) {
+	return a.attachMgr.CollectGarbage(false)
+}
+
+// AttachFiles copies files into the attachment directory by path.
+// Used for dropped files, the file dialog and --attach. Files that fail are
+// skipped and reported in the returned error.
+func (a *App) AttachFiles(paths []string) ([]attachment.Attachment, error) {
+	attachments := []attachment.Attachment{}
+	var errs []error
+	for _, path := range paths {
+		att, err := a.attachMgr.AttachFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		attachments = append(attachments, *att)
+	}
+	return attachments, errors.Join(errs...)
+}
+
+// SelectAttachmentFiles opens a file dialog and attaches the chosen files
+func (a *App) SelectAttachmentFiles() ([]attachment.Attachment, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Attach Files",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.AttachFiles(paths)
+}
+
+// attachAndNotify attaches files and pushes the markdown into the capture window
+func (a *App) attachAndNotify(paths []string) {
+	attachments, err := a.AttachFiles(paths)
+	if err != nil {
+		fmt.Printf("Error attaching files: %v\n", err)
+	}
+	if len(attachments) == 0 {
+		return
+	}
+
+	runtime.WindowShow(a.ctx)
+	if runtime.WindowIsMinimised(a.ctx) {
+		runtime.WindowUnminimise(a.ctx)
+	}
+	runtime.EventsEmit(a.ctx, "attachment:added", attachments)
+}
+
+// ConvertAttachmentLinks rewrites attachment links in every note to the given
+// style ("relative" or "absolute"). With dryRun set nothing is written.
+func (a *App) ConvertAttachmentLinks(style string, dryRun bool) (*attachment.ConvertReport, error) {
+	return attachment.ConvertLinks(a.cfg().Active().RootPath, style, dryRun)
+}
+
+// ListNoteDates returns the dates of the active notebook that have notes, newest first
 func (a *App) ListNoteDates() ([]string, error) {
-	return note.ListNoteDates(a.config.RootPath)
+	root, layout := a.active()
+	return a.dateIndex(root, layout).Dates()
 }
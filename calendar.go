@@ -0,0 +1,65 @@
+package main
+
+import (
+	"t-log/internal/fsutil"
+	"t-log/internal/note"
+)
+
+// notebookKey identifies a notebook's files: the same folder read with another
+// layout has other daily files
+type notebookKey struct {
+	root   string
+	layout note.Layout
+}
+
+// GetCalendar summarises the months from..to (YYYY-MM, inclusive) of the
+// active notebook: the days with notes, their entry counts and sizes, and the
+// daily files that are not where the layout expects them. Empty bounds extend
+// to the first or last month with notes.
+func (a *App) GetCalendar(from, to string) (*note.Calendar, error) {
+	root, layout := a.active()
+	return a.dateIndex(root, layout).Calendar(from, to)
+}
+
+// dateIndex returns the cached scan of a notebook, creating it on first use
+func (a *App) dateIndex(root string, layout note.Layout) *note.DateIndex {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := notebookKey{root, layout}
+	if a.indexes == nil {
+		a.indexes = map[notebookKey]*note.DateIndex{}
+	}
+	index, ok := a.indexes[key]
+	if !ok {
+		index = note.NewDateIndex(root, layout)
+		a.indexes[key] = index
+	}
+	return index
+}
+
+// invalidateNotes drops what the caches know about the daily files of dates
+// in the folder root, after they changed on disk
+func (a *App) invalidateNotes(root string, dates []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, index := range a.indexes {
+		if key.root == root {
+			index.Invalidate(dates)
+		}
+	}
+	for key, cache := range a.stats {
+		if key.root == root {
+			cache.Invalidate(dates)
+		}
+	}
+}
+
+// invalidateFile is invalidateNotes for a daily file whose notebook is not
+// known, such as one changed by undo
+func (a *App) invalidateFile(path, date string) {
+	for _, nb := range a.cfg().AllNotebooks() {
+		if fsutil.Within(nb.RootPath, path) {
+			a.invalidateNotes(nb.RootPath, []string{date})
+		}
+	}
+}
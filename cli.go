@@ -0,0 +1,110 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// cliArgs holds the command line options understood by t-log
+type cliArgs struct {
+	Attach     []string // Files to attach to the capture window (--attach <path>)
+	ConfigPath string   // Config file override (--config <path>)
+
+	// Headless export (--export <file> [--from date] [--to date] [--format f] [--group day|tag] [--notebook name])
+	Export   string
+	From     string
+	To       string
+	Format   string
+	Group    string
+	Notebook string // Also used by --site
+
+	Site string // Static site output folder (--site <folder>)
+}
+
+// parseArgs reads t-log options from args (without the program name).
+// Unknown arguments are ignored because Wails and the OS may pass their own.
+// Relative paths are resolved against workDir when it is set.
+func parseArgs(args []string, workDir string) cliArgs {
+	var parsed cliArgs
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--attach" || arg == "-attach":
+			if i+1 < len(args) {
+				i++
+				parsed.Attach = append(parsed.Attach, resolveArgPath(args[i], workDir))
+			}
+		case strings.HasPrefix(arg, "--attach="):
+			parsed.Attach = append(parsed.Attach, resolveArgPath(strings.TrimPrefix(arg, "--attach="), workDir))
+		case arg == "--config" || arg == "-config":
+			if i+1 < len(args) {
+				i++
+				parsed.ConfigPath = resolveArgPath(args[i], workDir)
+			}
+		case strings.HasPrefix(arg, "--config="):
+			parsed.ConfigPath = resolveArgPath(strings.TrimPrefix(arg, "--config="), workDir)
+		case arg == "--export" || arg == "-export":
+			if i+1 < len(args) {
+				i++
+				parsed.Export = resolveArgPath(args[i], workDir)
+			}
+		case strings.HasPrefix(arg, "--export="):
+			parsed.Export = resolveArgPath(strings.TrimPrefix(arg, "--export="), workDir)
+		case arg == "--site" || arg == "-site":
+			if i+1 < len(args) {
+				i++
+				parsed.Site = resolveArgPath(args[i], workDir)
+			}
+		case strings.HasPrefix(arg, "--site="):
+			parsed.Site = resolveArgPath(strings.TrimPrefix(arg, "--site="), workDir)
+		default:
+			if name, value, ok := exportOption(args, &i); ok {
+				switch name {
+				case "from":
+					parsed.From = value
+				case "to":
+					parsed.To = value
+				case "format":
+					parsed.Format = value
+				case "group":
+					parsed.Group = value
+				case "notebook":
+					parsed.Notebook = value
+				}
+			}
+		}
+	}
+
+	return parsed
+}
+
+// exportOption reads a plain-valued export option ("--from 2025-01-01" or
+// "--from=2025-01-01") at args[*i], advancing *i past its value
+func exportOption(args []string, i *int) (name, value string, ok bool) {
+	arg := strings.TrimLeft(args[*i], "-")
+	if arg == args[*i] {
+		return "", "", false
+	}
+	name, value, hasValue := strings.Cut(arg, "=")
+	switch name {
+	case "from", "to", "format", "group", "notebook":
+	default:
+		return "", "", false
+	}
+	if !hasValue {
+		if *i+1 >= len(args) {
+			return "", "", false
+		}
+		*i++
+		value = args[*i]
+	}
+	return name, value, true
+}
+
+func resolveArgPath(path, workDir string) string {
+	if workDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workDir, path)
+}
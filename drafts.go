@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/draft"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// draftDelay is how long typing must pause before the draft is written
+const draftDelay = 500 * time.Millisecond
+
+// startDrafts opens the draft store and keeps drafted attachments out of GC
+func (a *App) startDrafts() {
+	a.drafts = draft.NewStore(filepath.Join(a.configDir(), draft.DirName), draftDelay)
+	a.attachMgr.SetPinned(a.drafts.Attachments)
+}
+
+// registerDraftCommands registers the palette commands for draft history
+func (a *App) registerDraftCommands() {
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:restore-draft",
+		Title:       "Restore Draft...",
+		Description: "Bring back text that was cleared from the editor without saving",
+		Aliases:     []string{"draft", "recover", "unsaved"},
+		Params: []command.Param{
+			{Name: "draft", Prompt: "Choose a draft...", Required: true},
+		},
+	}, func(args command.Args) error {
+		d, err := a.RestoreDraft(args.Get("draft"))
+		if err != nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "draft:restore", d.Content)
+		return nil
+	})
+	a.cmdRegistry.SetParamProvider("cmd:restore-draft", "draft", func(prefix string) []command.Completion {
+		completions := []command.Completion{}
+		for _, d := range a.GetDraftHistory() {
+			if prefix != "" && !strings.Contains(strings.ToLower(d.Content), strings.ToLower(prefix)) {
+				continue
+			}
+			completions = append(completions, command.Completion{
+				Value:       d.ID,
+				Label:       firstLine(d.Content),
+				Description: d.UpdatedAt.Format("2006-01-02 15:04"),
+			})
+		}
+		return completions
+	})
+}
+
+// firstLine shortens a draft to a one-line label
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " …"
+	}
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:80]) + "…"
+	}
+	return s
+}
+
+// SaveDraft records the editor text. It is written to disk once typing
+// pauses; clearing the editor moves the draft to the history.
+func (a *App) SaveDraft(content string) {
+	if a.drafts == nil {
+		return
+	}
+	a.mu.Lock()
+	target := a.target
+	a.mu.Unlock()
+
+	var attachments []string
+	if strings.TrimSpace(content) != "" {
+		attachments = a.attachMgr.ReferencedFiles(content)
+	} else {
+		content = ""
+	}
+	a.drafts.Save(target, content, attachments)
+}
+
+// LoadDraft returns the unsaved draft, or nil when there is none
+func (a *App) LoadDraft() *draft.Draft {
+	if a.drafts == nil {
+		return nil
+	}
+	d, ok := a.drafts.Current()
+	if !ok {
+		return nil
+	}
+	return &d
+}
+
+// ClearDraft drops the current draft without keeping it in the history,
+// e.g. after it was run as a slash command
+func (a *App) ClearDraft() error {
+	if a.drafts == nil {
+		return nil
+	}
+	if err := a.drafts.Clear(); err != nil {
+		return fmt.Errorf("failed to clear draft: %w", err)
+	}
+	return nil
+}
+
+// GetDraftHistory returns drafts that were cleared without saving, newest first
+func (a *App) GetDraftHistory() []draft.Draft {
+	if a.drafts == nil {
+		return []draft.Draft{}
+	}
+	return a.drafts.History()
+}
+
+// RestoreDraft makes a draft from the history current again
+func (a *App) RestoreDraft(id string) (draft.Draft, error) {
+	if a.drafts == nil {
+		return draft.Draft{}, fmt.Errorf("drafts are not available")
+	}
+	return a.drafts.Restore(id)
+}
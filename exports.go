@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/config"
+	"t-log/internal/export"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// exportTemplateDir is the folder in the config dir whose <format>.tmpl
+// files replace the built-in export templates
+const exportTemplateDir = "export-templates"
+
+// exportOptions describes an export of notebook nb
+func exportOptions(nb config.Notebook, format, groupBy, configDir string) (export.Options, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return export.Options{}, err
+	}
+	g := export.GroupBy(groupBy)
+	switch g {
+	case "":
+		g = export.ByDay
+	case export.ByDay, export.ByTag:
+	default:
+		return export.Options{}, fmt.Errorf("unknown grouping %q (use day or tag)", groupBy)
+	}
+	return export.Options{
+		Format:      f,
+		GroupBy:     g,
+		Notebook:    nb.Name,
+		RootPath:    nb.RootPath,
+		Layout:      note.Layout(nb.Layout),
+		TemplateDir: filepath.Join(configDir, exportTemplateDir),
+	}, nil
+}
+
+// registerExportCommands registers the palette command for exports
+func (a *App) registerExportCommands() {
+	formats := make([]string, len(export.Formats))
+	for i, f := range export.Formats {
+		formats[i] = string(f)
+	}
+
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:export",
+		Title:       "Export Notes...",
+		Description: "Save a range of notes as Markdown, HTML, JSON, CSV or text",
+		Usage:       "export <start> [end] [format] [day|tag]",
+		Aliases:     []string{"save as", "html", "csv"},
+		Params: []command.Param{
+			{Name: "start", Type: command.ParamDate, Prompt: "From date (YYYY-MM-DD)...", Required: true},
+			{Name: "end", Type: command.ParamDate, Prompt: "To date (YYYY-MM-DD)...", Default: "today"},
+			{Name: "format", Type: command.ParamEnum, Prompt: "Format...", Options: formats, Default: string(export.Markdown)},
+			{Name: "group", Type: command.ParamEnum, Prompt: "Group by...", Options: []string{string(export.ByDay), string(export.ByTag)}, Default: string(export.ByDay)},
+		},
+	}, func(args command.Args) error {
+		path, err := a.ExportNotes(args.Get("start"), args.Get("end"), args.Get("format"), args.Get("group"))
+		if err != nil || path == "" {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "export:done", path)
+		return nil
+	})
+}
+
+// ExportNotes asks where to save and exports the active notebook's notes
+// between start and end (YYYY-MM-DD). It returns the written file, or ""
+// when the dialog was cancelled.
+func (a *App) ExportNotes(start, end, format, groupBy string) (string, error) {
+	nb := a.cfg().Active()
+	opts, err := exportOptions(nb, format, groupBy, a.configDir())
+	if err != nil {
+		return "", err
+	}
+	if start > end {
+		start, end = end, start
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Notes",
+		DefaultFilename: fmt.Sprintf("t-log-%s_%s%s", start, end, opts.Format.Ext()),
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(string(opts.Format)), Pattern: "*" + opts.Format.Ext()},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to choose export file: %w", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	if _, err := export.ToFile(path, start, end, opts); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// runExport handles "t-log --export <file>": it writes the export without
+// starting the UI. The format defaults to the file's extension and the
+// range to the last 7 days.
+func runExport(cfg *config.AppConfig, args cliArgs, configDir string) error {
+	nb, ok := cfg.FindNotebook(args.Notebook)
+	if args.Notebook == "" {
+		nb, ok = cfg.Active(), true
+	}
+	if !ok {
+		return fmt.Errorf("unknown notebook %q", args.Notebook)
+	}
+
+	format := args.Format
+	if format == "" {
+		format = filepath.Ext(args.Export)
+		if format == "" {
+			format = string(export.Markdown)
+		}
+	}
+	opts, err := exportOptions(nb, format, args.Group, configDir)
+	if err != nil {
+		return err
+	}
+
+	end := args.To
+	if end == "" {
+		end = time.Now().Format("2006-01-02")
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", end, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --to date %q (use YYYY-MM-DD)", end)
+	}
+	start := args.From
+	if start == "" {
+		start = endDate.AddDate(0, 0, -6).Format("2006-01-02")
+	} else if _, err := time.ParseInLocation("2006-01-02", start, time.Local); err != nil {
+		return fmt.Errorf("invalid --from date %q (use YYYY-MM-DD)", start)
+	}
+
+	doc, err := export.ToFile(args.Export, start, end, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d notes to %s\n", doc.Count, args.Export)
+	return nil
+}
@@ -1,5 +1,5 @@
 import { ref, reactive, computed, onMounted, onUnmounted, nextTick } from 'vue'
-import { SaveNote, HideWindow, GetRecentNotes, OpenDailyNote, OpenDateNote, GetDailyNotes } from '../../wailsjs/go/main/App'
+import { SaveNote, HideWindow, GetRecentNotes, OpenDailyNote, OpenDateNote, RunSlashCommand, ClearDraft, GetDailyNotes } from '../../wailsjs/go/main/App'
 import { WindowSetSize, EventsOn } from '../../wailsjs/runtime/runtime'
 
 // State Constants
@@ -32,12 +32,20 @@ export function useApp() {
     view: ViewState.DEFAULT,
     modal: ModalState.NONE,
     activity: ActivityState.IDLE,
-    contextMode: 'list' // 'list' | 'export'
+    contextMode: 'list' // 'list' | 'export' | 'stats'
   })
 
   const inputRef = ref(null)
   const recentNotes = ref([])
   let resetEventCancel = null
+  let configEventCancel = null
+  let notesEventCancel = null
+  let undoEventCancel = null
+  let changedEventCancel = null
+  let statsEventCancel = null
+  let currentView = null // Last NotesView shown in the panel, to refresh the same days
+  const contextRange = ref({ start: '', end: '' }) // Days of that view, for exporting them
+  const notesVersion = ref(0) // Bumped on every change on disk, for views that reload themselves
 
   // Computed Helpers
   const isContextPanelVisible = computed(() => appState.view === ViewState.CONTEXT_PANEL)
@@ -56,6 +64,18 @@ export function useApp() {
     }
   }
 
+  // Reloads what the history panel shows after notes changed
+  const refreshNotes = async () => {
+    if (!currentView) {
+      return loadNotes()
+    }
+    try {
+      recentNotes.value = await GetDailyNotes(currentView.start, currentView.end) || []
+    } catch (error) {
+      console.error('Failed to reload notes:', error)
+    }
+  }
+
   const hideAndReset = async () => {
     await HideWindow()
     appState.view = ViewState.DEFAULT 
@@ -82,49 +102,21 @@ export function useApp() {
     appState.modal = ModalState.NONE
   }
 
-  // Command Handler
-  const handleCommand = async (cmd) => {
-      const command = cmd.toLowerCase().trim()
-      
-      if (command === '/today' || command === '/list') {
-          const today = new Date().toISOString().split('T')[0]
-          try {
-              recentNotes.value = await GetDailyNotes(today, today) || []
-              toggleContextPanel('list')
-          } catch (err) {
-              console.error(err)
-          }
-          return
-      }
-      
-      if (command === '/week') {
-          const now = new Date()
-          const day = now.getDay() || 7 
-          if (day !== 1) now.setHours(-24 * (day - 1)) 
-          const startOfWeek = now.toISOString().split('T')[0]
-          const today = new Date().toISOString().split('T')[0]
-          
-          try {
-              recentNotes.value = await GetDailyNotes(startOfWeek, today) || []
-              toggleContextPanel('export')
-          } catch (err) {
-              console.error(err)
-          }
-          return
-      }
+  // Opens the history panel, keeping it open if it already is
+  const showContextPanel = (mode = 'list') => {
+    appState.view = ViewState.CONTEXT_PANEL
+    appState.contextMode = mode
+    WindowSetSize(EXPANDED_WIDTH, COLLAPSED_HEIGHT)
+  }
 
-      if (command === '/month') {
-        const now = new Date()
-        const startOfMonth = new Date(now.getFullYear(), now.getMonth(), 1).toISOString().split('T')[0]
-        const today = new Date().toISOString().split('T')[0]
-        
-        try {
-            recentNotes.value = await GetDailyNotes(startOfMonth, today) || []
-            toggleContextPanel('export')
-        } catch (err) {
-            console.error(err)
-        }
-        return
+  // Slash commands ("/today", "/days 14"...) run in the backend, which
+  // answers with a "notes:show" event
+  const handleCommand = async (cmd) => {
+    try {
+      await RunSlashCommand(cmd)
+    } catch (err) {
+      console.error(err)
+      alert(err)
     }
   }
 
@@ -139,6 +131,7 @@ export function useApp() {
       appState.activity = ActivityState.OPENING
       try {
         await OpenDailyNote()
+        await ClearDraft()
         await hideAndReset()
       } catch (error) {
         console.error('Failed to open editor:', error)
@@ -155,6 +148,7 @@ export function useApp() {
             appState.activity = ActivityState.OPENING
             try {
                 await OpenDateNote(dateStr)
+                await ClearDraft()
                 await hideAndReset()
             } catch (error) {
                 console.error('Failed to open date note:', error)
@@ -167,12 +161,8 @@ export function useApp() {
 
     try {
       await SaveNote(content)
-      if (isContextPanelVisible.value && appState.contextMode === 'list') {
-          const today = new Date().toISOString().split('T')[0]
-          recentNotes.value = await GetDailyNotes(today, today) || []
-      } else {
-          await loadNotes()
-      }
+      await ClearDraft()
+      await refreshNotes()
       
       await hideAndReset()
     } catch (error) {
@@ -246,6 +236,39 @@ export function useApp() {
       appState.view = ViewState.DEFAULT
       WindowSetSize(DEFAULT_WIDTH, COLLAPSED_HEIGHT)
     })
+
+    notesEventCancel = EventsOn("notes:show", (view) => {
+      currentView = view
+      contextRange.value = { start: view.start, end: view.end }
+      recentNotes.value = view.notes || []
+      showContextPanel(view.mode)
+    })
+
+    // "Undo Last Note" took an entry out of a daily file
+    undoEventCancel = EventsOn("note:undone", () => {
+      refreshNotes()
+    })
+
+    // A daily file changed on disk (external editor, another instance)
+    changedEventCancel = EventsOn("notes:changed", (change) => {
+      const dates = change.dates || []
+      notesVersion.value++
+      if (!currentView || dates.some(d => d >= currentView.start && d <= currentView.end)) {
+        refreshNotes()
+      }
+    })
+
+    // "Show Statistics": the panel loads the numbers for the range itself
+    statsEventCancel = EventsOn("stats:show", (view) => {
+      contextRange.value = { start: view.start, end: view.end }
+      showContextPanel('stats')
+    })
+
+    // Root path or history window may have changed (Settings or a hand edit)
+    configEventCancel = EventsOn("config:changed", () => {
+      notesVersion.value++
+      refreshNotes()
+    })
   })
 
   onUnmounted(() => {
@@ -254,6 +277,21 @@ export function useApp() {
     if (resetEventCancel) {
       resetEventCancel()
     }
+    if (configEventCancel) {
+      configEventCancel()
+    }
+    if (notesEventCancel) {
+      notesEventCancel()
+    }
+    if (undoEventCancel) {
+      undoEventCancel()
+    }
+    if (changedEventCancel) {
+      changedEventCancel()
+    }
+    if (statsEventCancel) {
+      statsEventCancel()
+    }
   })
 
   return {
@@ -262,6 +300,8 @@ export function useApp() {
     recentNotes,
     isContextPanelVisible,
     contextPanelMode,
+    contextRange,
+    notesVersion,
     isCommandPaletteVisible,
     isOpeningFile,
     isSaving,
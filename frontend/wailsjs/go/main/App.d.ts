@@ -1,39 +1,104 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {attachment} from '../models';
+import {site} from '../models';
 import {command} from '../models';
-import {config} from '../models';
 import {note} from '../models';
+import {config} from '../models';
+import {draft} from '../models';
+import {queue} from '../models';
+import {importer} from '../models';
+
+export function AttachFiles(arg1:Array<string>):Promise<Array<attachment.Attachment>>;
+
+export function BuildSite(arg1:string):Promise<site.Report>;
+
+export function CaptureNotebook():Promise<string>;
+
+export function ClearDraft():Promise<void>;
+
+export function CompleteCommandArg(arg1:string,arg2:string,arg3:string):Promise<Array<command.Completion>>;
+
+export function ConvertAttachmentLinks(arg1:string,arg2:boolean):Promise<attachment.ConvertReport>;
 
 export function ExecuteCommand(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function ExecuteCommandWithSelection(arg1:string,arg2:Array<string>,arg3:string):Promise<void>;
+
+export function ExportNotes(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function GenerateReport(arg1:string,arg2:string):Promise<string>;
+
+export function GetCalendar(arg1:string,arg2:string):Promise<note.Calendar>;
+
 export function GetCommands():Promise<Array<command.Command>>;
 
 export function GetConfig():Promise<config.AppConfig>;
 
+export function GetConfigPath():Promise<string>;
+
+export function GetConfigSource():Promise<string>;
+
 export function GetDailyNotes(arg1:string,arg2:string):Promise<Array<note.DailyNote>>;
 
+export function GetDraftHistory():Promise<Array<draft.Draft>>;
+
+export function GetNotebooks():Promise<Array<config.Notebook>>;
+
 export function GetNotesByDateRange(arg1:string,arg2:string):Promise<Array<note.NoteEntry>>;
 
+export function GetPendingCount():Promise<number>;
+
+export function GetPendingNotes():Promise<Array<queue.Item>>;
+
 export function GetRecentNotes():Promise<Array<note.DailyNote>>;
 
+export function GetSlashCommands():Promise<Array<command.Command>>;
+
+export function GetStats(arg1:string,arg2:string):Promise<note.Stats>;
+
 export function Greet(arg1:string):Promise<string>;
 
 export function HideWindow():Promise<void>;
 
+export function ImportNotes(arg1:string,arg2:string,arg3:boolean):Promise<importer.Report>;
+
 export function ListNoteDates():Promise<Array<string>>;
 
+export function LoadDraft():Promise<draft.Draft>;
+
 export function OpenDailyNote():Promise<void>;
 
 export function OpenDateNote(arg1:string):Promise<void>;
 
 export function OpenNoteAt(arg1:string,arg2:number):Promise<void>;
 
-export function SaveNote(arg1:string):Promise<void>;
+export function RestoreDraft(arg1:string):Promise<draft.Draft>;
+
+export function RunSlashCommand(arg1:string):Promise<void>;
+
+export function SaveDraft(arg1:string):Promise<void>;
+
+export function SaveNote(arg1:string):Promise<note.SavedEntry>;
+
+export function ScanOrphanedAttachments():Promise<attachment.GCReport>;
+
+export function SearchCommands(arg1:string):Promise<Array<command.Command>>;
 
 export function SearchNotes(arg1:string):Promise<Array<note.SearchResult>>;
 
+export function SelectAttachmentFiles():Promise<Array<attachment.Attachment>>;
+
 export function SelectRootPath():Promise<string>;
 
+export function SetActiveNotebook(arg1:string):Promise<void>;
+
+export function TrashOrphanedAttachments():Promise<attachment.GCReport>;
+
+export function UndoLastNote():Promise<string>;
+
 export function UpdateConfig(arg1:config.AppConfig):Promise<void>;
 
 export function UploadAttachment(arg1:Array<number>,arg2:string):Promise<string>;
+
+export function ValidateConfig(arg1:config.AppConfig):Promise<Array<config.FieldError>>;
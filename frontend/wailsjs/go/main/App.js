@@ -2,10 +2,50 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AttachFiles(arg1) {
+  return window['go']['main']['App']['AttachFiles'](arg1);
+}
+
+export function BuildSite(arg1) {
+  return window['go']['main']['App']['BuildSite'](arg1);
+}
+
+export function CaptureNotebook() {
+  return window['go']['main']['App']['CaptureNotebook']();
+}
+
+export function ClearDraft() {
+  return window['go']['main']['App']['ClearDraft']();
+}
+
+export function CompleteCommandArg(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompleteCommandArg'](arg1, arg2, arg3);
+}
+
+export function ConvertAttachmentLinks(arg1, arg2) {
+  return window['go']['main']['App']['ConvertAttachmentLinks'](arg1, arg2);
+}
+
 export function ExecuteCommand(arg1, arg2) {
   return window['go']['main']['App']['ExecuteCommand'](arg1, arg2);
 }
 
+export function ExecuteCommandWithSelection(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExecuteCommandWithSelection'](arg1, arg2, arg3);
+}
+
+export function ExportNotes(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportNotes'](arg1, arg2, arg3, arg4);
+}
+
+export function GenerateReport(arg1, arg2) {
+  return window['go']['main']['App']['GenerateReport'](arg1, arg2);
+}
+
+export function GetCalendar(arg1, arg2) {
+  return window['go']['main']['App']['GetCalendar'](arg1, arg2);
+}
+
 export function GetCommands() {
   return window['go']['main']['App']['GetCommands']();
 }
@@ -14,18 +54,50 @@ export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetConfigPath() {
+  return window['go']['main']['App']['GetConfigPath']();
+}
+
+export function GetConfigSource() {
+  return window['go']['main']['App']['GetConfigSource']();
+}
+
 export function GetDailyNotes(arg1, arg2) {
   return window['go']['main']['App']['GetDailyNotes'](arg1, arg2);
 }
 
+export function GetDraftHistory() {
+  return window['go']['main']['App']['GetDraftHistory']();
+}
+
+export function GetNotebooks() {
+  return window['go']['main']['App']['GetNotebooks']();
+}
+
 export function GetNotesByDateRange(arg1, arg2) {
   return window['go']['main']['App']['GetNotesByDateRange'](arg1, arg2);
 }
 
+export function GetPendingCount() {
+  return window['go']['main']['App']['GetPendingCount']();
+}
+
+export function GetPendingNotes() {
+  return window['go']['main']['App']['GetPendingNotes']();
+}
+
 export function GetRecentNotes() {
   return window['go']['main']['App']['GetRecentNotes']();
 }
 
+export function GetSlashCommands() {
+  return window['go']['main']['App']['GetSlashCommands']();
+}
+
+export function GetStats(arg1, arg2) {
+  return window['go']['main']['App']['GetStats'](arg1, arg2);
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
@@ -34,10 +106,18 @@ export function HideWindow() {
   return window['go']['main']['App']['HideWindow']();
 }
 
+export function ImportNotes(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportNotes'](arg1, arg2, arg3);
+}
+
 export function ListNoteDates() {
   return window['go']['main']['App']['ListNoteDates']();
 }
 
+export function LoadDraft() {
+  return window['go']['main']['App']['LoadDraft']();
+}
+
 export function OpenDailyNote() {
   return window['go']['main']['App']['OpenDailyNote']();
 }
@@ -50,18 +130,54 @@ export function OpenNoteAt(arg1, arg2) {
   return window['go']['main']['App']['OpenNoteAt'](arg1, arg2);
 }
 
+export function RestoreDraft(arg1) {
+  return window['go']['main']['App']['RestoreDraft'](arg1);
+}
+
+export function RunSlashCommand(arg1) {
+  return window['go']['main']['App']['RunSlashCommand'](arg1);
+}
+
+export function SaveDraft(arg1) {
+  return window['go']['main']['App']['SaveDraft'](arg1);
+}
+
 export function SaveNote(arg1) {
   return window['go']['main']['App']['SaveNote'](arg1);
 }
 
+export function ScanOrphanedAttachments() {
+  return window['go']['main']['App']['ScanOrphanedAttachments']();
+}
+
+export function SearchCommands(arg1) {
+  return window['go']['main']['App']['SearchCommands'](arg1);
+}
+
 export function SearchNotes(arg1) {
   return window['go']['main']['App']['SearchNotes'](arg1);
 }
 
+export function SelectAttachmentFiles() {
+  return window['go']['main']['App']['SelectAttachmentFiles']();
+}
+
 export function SelectRootPath() {
   return window['go']['main']['App']['SelectRootPath']();
 }
 
+export function SetActiveNotebook(arg1) {
+  return window['go']['main']['App']['SetActiveNotebook'](arg1);
+}
+
+export function TrashOrphanedAttachments() {
+  return window['go']['main']['App']['TrashOrphanedAttachments']();
+}
+
+export function UndoLastNote() {
+  return window['go']['main']['App']['UndoLastNote']();
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['main']['App']['UpdateConfig'](arg1);
 }
@@ -69,3 +185,7 @@ export function UpdateConfig(arg1) {
 export function UploadAttachment(arg1, arg2) {
   return window['go']['main']['App']['UploadAttachment'](arg1, arg2);
 }
+
+export function ValidateConfig(arg1) {
+  return window['go']['main']['App']['ValidateConfig'](arg1);
+}
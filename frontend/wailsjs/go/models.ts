@@ -1,10 +1,143 @@
+export namespace attachment {
+	
+	export class Attachment {
+	    name: string;
+	    webPath: string;
+	    mime: string;
+	    size: number;
+	    isImage: boolean;
+	    markdown: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Attachment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.webPath = source["webPath"];
+	        this.mime = source["mime"];
+	        this.size = source["size"];
+	        this.isImage = source["isImage"];
+	        this.markdown = source["markdown"];
+	    }
+	}
+	export class ConvertReport {
+	    dryRun: boolean;
+	    style: string;
+	    filesScanned: number;
+	    filesChanged: number;
+	    links: number;
+	    changes: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConvertReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.style = source["style"];
+	        this.filesScanned = source["filesScanned"];
+	        this.filesChanged = source["filesChanged"];
+	        this.links = source["links"];
+	        this.changes = source["changes"];
+	    }
+	}
+	export class OrphanFile {
+	    path: string;
+	    webPath: string;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrphanFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.webPath = source["webPath"];
+	        this.size = source["size"];
+	    }
+	}
+	export class GCReport {
+	    dryRun: boolean;
+	    scanned: number;
+	    referenced: number;
+	    orphans: OrphanFile[];
+	    totalSize: number;
+	    moved: number;
+	    trashDir: string;
+	    purged: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GCReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.scanned = source["scanned"];
+	        this.referenced = source["referenced"];
+	        this.orphans = this.convertValues(source["orphans"], OrphanFile);
+	        this.totalSize = source["totalSize"];
+	        this.moved = source["moved"];
+	        this.trashDir = source["trashDir"];
+	        this.purged = source["purged"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace command {
 	
+	export class Param {
+	    name: string;
+	    type: string;
+	    prompt: string;
+	    required: boolean;
+	    default: string;
+	    options: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Param(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.prompt = source["prompt"];
+	        this.required = source["required"];
+	        this.default = source["default"];
+	        this.options = source["options"];
+	    }
+	}
 	export class Command {
 	    id: string;
 	    title: string;
 	    description: string;
 	    usage: string;
+	    aliases: string[];
+	    params: Param[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Command(source);
@@ -16,6 +149,42 @@ export namespace command {
 	        this.title = source["title"];
 	        this.description = source["description"];
 	        this.usage = source["usage"];
+	        this.aliases = source["aliases"];
+	        this.params = this.convertValues(source["params"], Param);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Completion {
+	    value: string;
+	    label: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Completion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.label = source["label"];
+	        this.description = source["description"];
 	    }
 	}
 
@@ -23,10 +192,60 @@ export namespace command {
 
 export namespace config {
 	
+	export class ImageConfig {
+	    enabled: boolean;
+	    max_width: number;
+	    max_height: number;
+	    format: string;
+	    quality: number;
+	    strip_metadata: boolean;
+	    thumbnail_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.max_width = source["max_width"];
+	        this.max_height = source["max_height"];
+	        this.format = source["format"];
+	        this.quality = source["quality"];
+	        this.strip_metadata = source["strip_metadata"];
+	        this.thumbnail_size = source["thumbnail_size"];
+	    }
+	}
+	export class Notebook {
+	    name: string;
+	    root_path: string;
+	    layout: string;
+	    hotkey: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Notebook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.root_path = source["root_path"];
+	        this.layout = source["layout"];
+	        this.hotkey = source["hotkey"];
+	    }
+	}
 	export class AppConfig {
+	    version: number;
 	    root_path: string;
+	    layout: string;
 	    hotkey: string;
+	    notebooks: Notebook[];
+	    active_notebook: string;
 	    history_days: number;
+	    image: ImageConfig;
+	    attachment_trash_days: number;
+	    attachment_links: string;
+	    undo_minutes: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -34,9 +253,136 @@ export namespace config {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
 	        this.root_path = source["root_path"];
+	        this.layout = source["layout"];
 	        this.hotkey = source["hotkey"];
+	        this.notebooks = this.convertValues(source["notebooks"], Notebook);
+	        this.active_notebook = source["active_notebook"];
 	        this.history_days = source["history_days"];
+	        this.image = this.convertValues(source["image"], ImageConfig);
+	        this.attachment_trash_days = source["attachment_trash_days"];
+	        this.attachment_links = source["attachment_links"];
+	        this.undo_minutes = source["undo_minutes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	
+
+}
+
+export namespace draft {
+	
+	export class Draft {
+	    id: string;
+	    notebook: string;
+	    content: string;
+	    attachments: string[];
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Draft(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.notebook = source["notebook"];
+	        this.content = source["content"];
+	        this.attachments = source["attachments"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace importer {
+	
+	export class Report {
+	    dryRun: boolean;
+	    kind: string;
+	    source: string;
+	    files: number;
+	    found: number;
+	    imported: number;
+	    duplicates: number;
+	    attachments: number;
+	    missing: string[];
+	    skipped: string[];
+	    start: string;
+	    end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.kind = source["kind"];
+	        this.source = source["source"];
+	        this.files = source["files"];
+	        this.found = source["found"];
+	        this.imported = source["imported"];
+	        this.duplicates = source["duplicates"];
+	        this.attachments = source["attachments"];
+	        this.missing = source["missing"];
+	        this.skipped = source["skipped"];
+	        this.start = source["start"];
+	        this.end = source["end"];
 	    }
 	}
 
@@ -44,6 +390,113 @@ export namespace config {
 
 export namespace note {
 	
+	export class MisplacedFile {
+	    date: string;
+	    path: string;
+	    expected: string;
+	    duplicate: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MisplacedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.path = source["path"];
+	        this.expected = source["expected"];
+	        this.duplicate = source["duplicate"];
+	    }
+	}
+	export class CalendarDay {
+	    date: string;
+	    entries: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalendarDay(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.entries = source["entries"];
+	        this.size = source["size"];
+	    }
+	}
+	export class MonthSummary {
+	    month: string;
+	    days: CalendarDay[];
+	    entries: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MonthSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.days = this.convertValues(source["days"], CalendarDay);
+	        this.entries = source["entries"];
+	        this.size = source["size"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Calendar {
+	    from: string;
+	    to: string;
+	    months: MonthSummary[];
+	    misplaced: MisplacedFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Calendar(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.months = this.convertValues(source["months"], MonthSummary);
+	        this.misplaced = this.convertValues(source["misplaced"], MisplacedFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 	export class DailyNote {
 	    date: string;
 	    content: string;
@@ -58,6 +511,28 @@ export namespace note {
 	        this.content = source["content"];
 	    }
 	}
+	export class DayStats {
+	    date: string;
+	    entries: number;
+	    words: number;
+	    attachments: number;
+	    attachmentBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.entries = source["entries"];
+	        this.words = source["words"];
+	        this.attachments = source["attachments"];
+	        this.attachmentBytes = source["attachmentBytes"];
+	    }
+	}
+	
+	
 	export class NoteEntry {
 	    content: string;
 	    timestamp: string;
@@ -74,6 +549,47 @@ export namespace note {
 	        this.date = source["date"];
 	    }
 	}
+	export class SavedEntry {
+	    id: string;
+	    file: string;
+	    offset: number;
+	    content: string;
+	    // Go type: time
+	    savedAt: any;
+	    queued: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.file = source["file"];
+	        this.offset = source["offset"];
+	        this.content = source["content"];
+	        this.savedAt = this.convertValues(source["savedAt"], null);
+	        this.queued = source["queued"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class SearchResult {
 	    content: string;
 	    date: string;
@@ -94,6 +610,174 @@ export namespace note {
 	        this.lineNo = source["lineNo"];
 	    }
 	}
+	export class TagTrend {
+	    tag: string;
+	    total: number;
+	    byMonth: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagTrend(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.total = source["total"];
+	        this.byMonth = source["byMonth"];
+	    }
+	}
+	export class Streak {
+	    start: string;
+	    end: string;
+	    days: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Streak(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.days = source["days"];
+	    }
+	}
+	export class Stats {
+	    start: string;
+	    end: string;
+	    days: DayStats[];
+	    activeDays: number;
+	    entries: number;
+	    words: number;
+	    hours: number[];
+	    weekdays: number[];
+	    busiestHour: number;
+	    currentStreak: Streak;
+	    longestStreak: Streak;
+	    tags: TagTrend[];
+	    attachments: number;
+	    attachmentBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.days = this.convertValues(source["days"], DayStats);
+	        this.activeDays = source["activeDays"];
+	        this.entries = source["entries"];
+	        this.words = source["words"];
+	        this.hours = source["hours"];
+	        this.weekdays = source["weekdays"];
+	        this.busiestHour = source["busiestHour"];
+	        this.currentStreak = this.convertValues(source["currentStreak"], Streak);
+	        this.longestStreak = this.convertValues(source["longestStreak"], Streak);
+	        this.tags = this.convertValues(source["tags"], TagTrend);
+	        this.attachments = source["attachments"];
+	        this.attachmentBytes = source["attachmentBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
+export namespace queue {
+	
+	export class Item {
+	    id: string;
+	    notebook: string;
+	    root_path: string;
+	    layout: string;
+	    content: string;
+	    // Go type: time
+	    created_at: any;
+	    attempts: number;
+	    last_error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Item(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.notebook = source["notebook"];
+	        this.root_path = source["root_path"];
+	        this.layout = source["layout"];
+	        this.content = source["content"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.attempts = source["attempts"];
+	        this.last_error = source["last_error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace site {
+	
+	export class Report {
+	    outDir: string;
+	    index: string;
+	    days: number;
+	    entries: number;
+	    tags: number;
+	    attachments: number;
+	    pages: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.outDir = source["outDir"];
+	        this.index = source["index"];
+	        this.days = source["days"];
+	        this.entries = source["entries"];
+	        this.tags = source["tags"];
+	        this.attachments = source["attachments"];
+	        this.pages = source["pages"];
+	    }
+	}
 
 }
 
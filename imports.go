@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/importer"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// registerImportCommands registers the palette command importing other apps' logs
+func (a *App) registerImportCommands() {
+	kinds := make([]string, len(importer.Kinds))
+	for i, k := range importer.Kinds {
+		kinds[i] = string(k)
+	}
+
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:import",
+		Title:       "Import Notes...",
+		Description: "Bring in Obsidian or Logseq daily notes, text logs, Day One/JSON or CSV exports",
+		Usage:       "import <format> <path> [preview|apply]",
+		Aliases:     []string{"obsidian", "logseq", "day one", "migrate"},
+		Params: []command.Param{
+			{Name: "format", Type: command.ParamEnum, Prompt: "Import from...", Options: kinds, Required: true},
+			{Name: "path", Type: command.ParamPath, Prompt: "File or folder to import...", Required: true},
+			{Name: "mode", Type: command.ParamEnum, Options: []string{"preview", "apply"}, Default: "preview"},
+		},
+	}, func(args command.Args) error {
+		// Like attachment cleanup, a preview is a dry run; the frontend shows
+		// the report and re-runs the command with "apply" once confirmed
+		report, err := a.ImportNotes(args.Get("format"), args.Get("path"), args.Get("mode") != "apply")
+		if err != nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "import:report", report)
+		return nil
+	})
+}
+
+// ImportNotes imports src (a file or folder) of the given format into the
+// active notebook, or only reports what would be imported when dryRun is set
+func (a *App) ImportNotes(format, src string, dryRun bool) (*importer.Report, error) {
+	kind, err := importer.ParseKind(format)
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.cfg()
+	nb := cfg.Active()
+	report, err := importer.Run(kind, src, importer.Options{
+		RootPath:  nb.RootPath,
+		Layout:    note.Layout(nb.Layout),
+		LinkStyle: cfg.AttachmentLinks,
+		DryRun:    dryRun,
+	})
+	// Also after a failure part way: the entries before it were written
+	if report != nil && !report.DryRun && report.Imported > 0 {
+		a.invalidateNotes(nb.RootPath, datesBetween(report.Start, report.End))
+	}
+	return report, err
+}
+
+// datesBetween lists the days start..end (YYYY-MM-DD, inclusive)
+func datesBetween(start, end string) []string {
+	first, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil
+	}
+	last, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil
+	}
+	var dates []string
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates
+}
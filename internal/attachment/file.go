@@ -0,0 +1,148 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxProcessSize is the largest image read into memory for the image pipeline.
+// Bigger files are streamed to disk unchanged.
+const maxProcessSize = 50 * 1024 * 1024
+
+// Attachment describes a file stored in the attachment directory
+type Attachment struct {
+	Name     string `json:"name"`     // Original file name
+	WebPath  string `json:"webPath"`  // /attachments/... path to embed in notes
+	MIME     string `json:"mime"`     // Detected content type
+	Size     int64  `json:"size"`     // Stored size in bytes
+	IsImage  bool   `json:"isImage"`  // Whether the file is embedded as an image
+	Markdown string `json:"markdown"` // Ready-to-insert markdown snippet
+}
+
+// AttachFile copies the file at srcPath into the attachment directory.
+// Files are streamed so large attachments never have to fit in memory;
+// only images small enough for the image pipeline are read fully.
+func (m *Manager) AttachFile(srcPath string) (*Attachment, error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", srcPath, err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot attach a directory: %s", srcPath)
+	}
+
+	// Sniff the first 512 bytes, then rewind so the copy starts from the beginning
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read %s: %w", srcPath, err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(srcPath)
+	mimeType := DetectMIME(name, head[:n])
+	isImage := strings.HasPrefix(mimeType, "image/")
+
+	// Images go through SaveAttachment so they get resized and thumbnailed
+	if isImage && m.currentConfig().Image.Enabled && info.Size() <= maxProcessSize {
+		content, err := io.ReadAll(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", srcPath, err)
+		}
+		fullPath, link, err := m.saveContent(content, name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		return newAttachment(name, link, mimeType, info.Size(), isImage), nil
+	}
+
+	dir, err := m.EnsureDir()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dst, newFilename, err := createUnique(dir, now, sanitizeFilename(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+	fullPath := dst.Name()
+	size, err := io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(fullPath)
+		return nil, fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	return newAttachment(name, m.link(now, newFilename), mimeType, size, isImage), nil
+}
+
+func newAttachment(name, path, mimeType string, size int64, isImage bool) *Attachment {
+	att := &Attachment{
+		Name:    name,
+		WebPath: path,
+		MIME:    mimeType,
+		Size:    size,
+		IsImage: isImage,
+	}
+
+	// Brackets in the label would terminate the markdown link early
+	label := strings.NewReplacer("[", "(", "]", ")").Replace(name)
+	if isImage {
+		att.Markdown = fmt.Sprintf("![%s](%s)", label, path)
+	} else {
+		att.Markdown = fmt.Sprintf("[%s](%s) (%s)", label, path, FormatSize(size))
+	}
+	return att
+}
+
+// DetectMIME guesses a content type from the file's leading bytes, falling back
+// to the extension when sniffing only yields a generic type
+func DetectMIME(name string, head []byte) string {
+	sniffed := http.DetectContentType(head)
+	byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+
+	switch {
+	case byExt == "":
+		return sniffed
+	case sniffed == "application/octet-stream",
+		strings.HasPrefix(sniffed, "text/plain"),
+		sniffed == "application/zip": // docx, xlsx, epub... are zip containers
+		return byExt
+	}
+	return sniffed
+}
+
+// FormatSize renders a byte count in human readable form (e.g. "1.2 MB")
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,56 @@
+package attachment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateUniqueSameMillisecond(t *testing.T) {
+	dir := t.TempDir()
+	now := time.UnixMilli(1736899200123)
+
+	names := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		f, name, err := createUnique(dir, now, "photo.png")
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+		if _, err := f.WriteString(name); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		if names[name] {
+			t.Fatalf("name %s handed out twice", name)
+		}
+		names[name] = true
+	}
+
+	for _, want := range []string{"1736899200123_photo.png", "1736899200123-1_photo.png", "1736899200123-2_photo.png"} {
+		data, err := os.ReadFile(filepath.Join(dir, want))
+		if err != nil {
+			t.Errorf("missing %s: %v", want, err)
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s was overwritten with %q", want, data)
+		}
+	}
+}
+
+func TestFindByFilenameWithCounter(t *testing.T) {
+	root := t.TempDir()
+	now := time.UnixMilli(1736899200123)
+	dir := filepath.Join(root, now.Format("2006"), now.Format("01"), "Attachment")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	name := uniqueFilename(now, "photo.png", 1)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := findByFilename(root, name); got != filepath.Join(dir, name) {
+		t.Errorf("findByFilename(%q) = %q", name, got)
+	}
+}
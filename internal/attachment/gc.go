@@ -0,0 +1,295 @@
+package attachment
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"t-log/internal/note"
+)
+
+// TrashDirName is the folder under RootPath where orphaned attachments are moved
+const TrashDirName = ".trash"
+
+// OrphanFile is an attachment that no daily note references any more
+type OrphanFile struct {
+	Path    string `json:"path"`    // Absolute path on disk
+	WebPath string `json:"webPath"` // /attachments/... form used in notes
+	Size    int64  `json:"size"`    // Size in bytes
+}
+
+// GCReport describes the result of an attachment garbage collection run
+type GCReport struct {
+	DryRun     bool         `json:"dryRun"`     // True when nothing was moved
+	Scanned    int          `json:"scanned"`    // Attachment files examined
+	Referenced int          `json:"referenced"` // Files still referenced by a note
+	Orphans    []OrphanFile `json:"orphans"`    // Unreferenced files
+	TotalSize  int64        `json:"totalSize"`  // Combined size of orphans in bytes
+	Moved      int          `json:"moved"`      // Orphans moved to trash
+	TrashDir   string       `json:"trashDir"`   // Where orphans were moved
+	Purged     int          `json:"purged"`     // Expired trash folders deleted
+}
+
+// attachmentRefRegex matches /attachments/... links inside markdown
+var attachmentRefRegex = regexp.MustCompile(`/attachments/([^\s)"'<>\]]+)`)
+
+// CollectGarbage finds unreferenced attachments. Unless dryRun is set they are moved
+// to {RootPath}/.trash/{YYYY-MM-DD}/ and trash older than the retention period is purged.
+func (m *Manager) CollectGarbage(dryRun bool) (*GCReport, error) {
+	rootPath := m.currentConfig().Active().RootPath
+
+	report, err := ScanOrphans(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	report.keepPinned(m.pinnedFiles())
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+
+	trashDir := filepath.Join(rootPath, TrashDirName, time.Now().Format("2006-01-02"))
+	moved, err := MoveToTrash(rootPath, trashDir, report.Orphans)
+	report.Moved = moved
+	report.TrashDir = trashDir
+	if err != nil {
+		return report, err
+	}
+
+	purged, err := PurgeTrash(rootPath, m.currentConfig().AttachmentTrashDays)
+	report.Purged = purged
+	return report, err
+}
+
+// ScanOrphans reads every markdown file under rootPath and lists attachment
+// files that none of them reference. It never modifies anything.
+func ScanOrphans(rootPath string) (*GCReport, error) {
+	refs, err := collectReferences(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GCReport{DryRun: true, Orphans: []OrphanFile{}}
+
+	err = filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == TrashDirName || d.Name() == ThumbDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Only files directly inside an Attachment folder are attachments
+		if filepath.Base(filepath.Dir(path)) != "Attachment" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			return nil
+		}
+
+		report.Scanned++
+		if refs[filepath.ToSlash(rel)] {
+			report.Referenced++
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		report.Orphans = append(report.Orphans, OrphanFile{
+			Path:    path,
+			WebPath: "/attachments/" + escapePath(filepath.ToSlash(rel)),
+			Size:    info.Size(),
+		})
+		report.TotalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		return report.Orphans[i].Path < report.Orphans[j].Path
+	})
+	return report, nil
+}
+
+// collectReferences returns the set of attachment paths (relative to rootPath,
+// slash separated, unescaped) referenced from any markdown file, in either link style.
+func collectReferences(rootPath string) (map[string]bool, error) {
+	refs := make(map[string]bool)
+
+	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == TrashDirName || d.Name() == "Attachment" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// An unreadable note could hold references; refuse to guess
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		addReferences(refs, rootPath, path, string(content))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+// addReferences adds the attachments linked from content, read as the note at
+// notePath, to refs
+func addReferences(refs map[string]bool, rootPath, notePath, content string) {
+	for _, match := range attachmentRefRegex.FindAllStringSubmatch(content, -1) {
+		ref := match[1]
+		// Drop query strings such as ?thumb=1
+		if i := strings.IndexAny(ref, "?#"); i >= 0 {
+			ref = ref[:i]
+		}
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		refs[ref] = true
+	}
+	// Portable links are relative to the note: Attachment/x.png, ../../2025/01/Attachment/x.png
+	for _, match := range markdownTargetRegex.FindAllStringSubmatch(content, -1) {
+		if ref := relativeTarget(rootPath, notePath, match[1]); ref != "" {
+			refs[ref] = true
+		}
+	}
+}
+
+// References returns the attachments content links to, read as the note at
+// notePath, as sorted absolute paths. The files are not checked to exist.
+func References(rootPath, notePath, content string) []string {
+	refs := make(map[string]bool)
+	addReferences(refs, rootPath, notePath, content)
+
+	files := make([]string, 0, len(refs))
+	for ref := range refs {
+		files = append(files, filepath.Join(rootPath, filepath.FromSlash(ref)))
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ReferencedFiles returns the attachment files content links to, read as if
+// it were saved to today's note in the capture notebook. Drafts record them
+// so garbage collection leaves their attachments alone.
+func (m *Manager) ReferencedFiles(content string) []string {
+	nb := m.currentNotebook()
+	return References(nb.RootPath, note.Layout(nb.Layout).DailyFile(nb.RootPath, time.Now()), content)
+}
+
+// keepPinned drops the orphans that pinned (absolute paths) still needs
+func (r *GCReport) keepPinned(pinned []string) {
+	if len(pinned) == 0 {
+		return
+	}
+	keep := make(map[string]bool, len(pinned))
+	for _, path := range pinned {
+		keep[filepath.Clean(path)] = true
+	}
+
+	orphans := r.Orphans[:0]
+	for _, orphan := range r.Orphans {
+		if keep[filepath.Clean(orphan.Path)] {
+			r.Referenced++
+			r.TotalSize -= orphan.Size
+			continue
+		}
+		orphans = append(orphans, orphan)
+	}
+	r.Orphans = orphans
+}
+
+// MoveToTrash moves orphans (and their thumbnails) into trashDir, keeping their
+// YYYY/MM/Attachment layout so they can be restored by moving them back.
+func MoveToTrash(rootPath, trashDir string, orphans []OrphanFile) (int, error) {
+	moved := 0
+	for _, orphan := range orphans {
+		rel, err := filepath.Rel(rootPath, orphan.Path)
+		if err != nil {
+			return moved, err
+		}
+		dest := filepath.Join(trashDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return moved, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+		if err := os.Rename(orphan.Path, dest); err != nil {
+			return moved, fmt.Errorf("failed to move %s to trash: %w", orphan.Path, err)
+		}
+		moved++
+
+		// The thumbnail is useless without its original
+		if thumb := ThumbnailFile(orphan.Path); thumb != "" {
+			os.Remove(thumb)
+		}
+	}
+	return moved, nil
+}
+
+// PurgeTrash deletes trash folders older than retentionDays.
+// A retention of 0 or less keeps trash forever.
+func PurgeTrash(rootPath string, retentionDays int) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	trashRoot := filepath.Join(rootPath, TrashDirName)
+	entries, err := os.ReadDir(trashRoot)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	purged := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		day, err := time.ParseInLocation("2006-01-02", entry.Name(), time.Local)
+		if err != nil || !day.Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(trashRoot, entry.Name())); err != nil {
+			return purged, fmt.Errorf("failed to purge trash: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// escapePath URL-escapes each segment of a slash separated path
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,383 @@
+package attachment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	// Registered so image.DecodeConfig recognises GIFs; we never re-encode them
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+
+	"t-log/internal/config"
+)
+
+// ThumbDirName is the folder (inside each Attachment directory) holding thumbnails
+const ThumbDirName = ".thumbs"
+
+// processImage runs the configured pipeline over an image attachment.
+// It returns the bytes to store and the file extension matching them.
+// Content that is not a PNG or JPEG is returned untouched.
+func processImage(content []byte, ext string, cfg config.ImageConfig) ([]byte, string, error) {
+	imgCfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || (format != "png" && format != "jpeg") {
+		// Not an image we know how to process (GIFs would lose their animation)
+		return content, ext, nil
+	}
+
+	target := format
+	switch strings.ToLower(cfg.Format) {
+	case "jpeg", "jpg":
+		target = "jpeg"
+	case "png":
+		target = "png"
+	}
+
+	// Phone photos are often stored sideways with an EXIF tag saying how to
+	// turn them. Re-encoding or stripping drops the tag, so the pixels have to
+	// be turned first.
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(content)
+	}
+	srcW, srcH := imgCfg.Width, imgCfg.Height
+	if orientation >= 5 {
+		srcW, srcH = srcH, srcW
+	}
+
+	width, height := fitWithin(srcW, srcH, cfg.MaxWidth, cfg.MaxHeight)
+	needsResize := width != srcW || height != srcH
+	needsRotate := orientation > 1 && cfg.StripMetadata
+
+	if !needsResize && !needsRotate && target == format {
+		// Avoid a lossy round-trip when nothing about the pixels changes
+		if cfg.StripMetadata {
+			stripped, err := stripMetadata(content, format)
+			if err != nil {
+				return nil, "", err
+			}
+			return stripped, ext, nil
+		}
+		return content, ext, nil
+	}
+
+	// Decoding and re-encoding drops all metadata as a side effect
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	dst := applyOrientation(src, orientation)
+	if needsResize {
+		dst = scale(dst, width, height)
+	}
+
+	data, err := encodeImage(dst, target, cfg.Quality)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, extensionFor(target, ext), nil
+}
+
+// generateThumbnail renders a JPEG thumbnail whose longest edge is at most size pixels.
+// ok is false when the content is not a decodable still image.
+func generateThumbnail(content []byte, size int) (data []byte, ok bool, err error) {
+	src, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, false, nil
+	}
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(content))
+	}
+
+	b := src.Bounds()
+	width, height := fitWithin(b.Dx(), b.Dy(), size, size)
+
+	data, err = encodeImage(scale(src, width, height), "jpeg", 80)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// fitWithin scales width x height down (never up) to fit inside maxW x maxH.
+// A zero limit means that dimension is unconstrained.
+func fitWithin(width, height, maxW, maxH int) (int, int) {
+	ratio := 1.0
+	if maxW > 0 && width > maxW {
+		ratio = float64(maxW) / float64(width)
+	}
+	if maxH > 0 && height > maxH {
+		if r := float64(maxH) / float64(height); r < ratio {
+			ratio = r
+		}
+	}
+	if ratio == 1.0 {
+		return width, height
+	}
+
+	w := int(float64(width)*ratio + 0.5)
+	h := int(float64(height)*ratio + 0.5)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, 1 when it
+// has none or the EXIF data can't be read
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF {
+		marker := data[i+1]
+		if marker == 0xDA {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end > len(data) {
+			break
+		}
+		if payload := data[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return exifOrientation(payload[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+// exifOrientation reads the Orientation tag of the first IFD of a TIFF block
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 { // Orientation, a SHORT
+			if o := int(order.Uint16(tiff[entry+8 : entry+10])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// applyOrientation turns and mirrors img so that it displays upright without
+// its EXIF orientation tag
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// Source pixel shown at (x, y)
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = w-1-x, y
+			case 3: // Upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // Mirrored, turned left
+				sx, sy = y, x
+			case 6: // Turned left, needs a turn right
+				sx, sy = y, h-1-x
+			case 7: // Mirrored, turned right
+				sx, sy = w-1-y, h-1-x
+			case 8: // Turned right, needs a turn left
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+func scale(src image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case "jpeg":
+		if quality <= 0 || quality > 100 {
+			quality = jpeg.DefaultQuality
+		}
+		// JPEG has no alpha channel, so flatten transparent screenshots onto white
+		// instead of letting the encoder turn them black
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+	case "png":
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := enc.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode png: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func extensionFor(format, fallback string) string {
+	switch format {
+	case "jpeg":
+		return ".jpg"
+	case "png":
+		return ".png"
+	}
+	return fallback
+}
+
+// stripMetadata removes EXIF/GPS and textual metadata without re-encoding pixels
+func stripMetadata(content []byte, format string) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		return stripJPEGMetadata(content)
+	case "png":
+		return stripPNGMetadata(content)
+	}
+	return content, nil
+}
+
+// stripJPEGMetadata drops APPn segments (EXIF, XMP, Photoshop IRB...) and comments.
+// JFIF (APP0), ICC profiles (APP2) and the Adobe colour transform marker (APP14)
+// are kept because decoders need them to render colours correctly.
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("invalid jpeg header")
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, fmt.Errorf("invalid jpeg marker at offset %d", i)
+		}
+		marker := data[i+1]
+
+		// Start of scan: everything after this is entropy-coded image data
+		if marker == 0xDA {
+			return append(out, data[i:]...), nil
+		}
+
+		segLen := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + segLen
+		if segLen < 2 || end > len(data) {
+			return nil, fmt.Errorf("truncated jpeg segment at offset %d", i)
+		}
+		payload := data[i+4 : end]
+
+		if !dropJPEGSegment(marker, payload) {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	return nil, fmt.Errorf("jpeg has no image data")
+}
+
+func dropJPEGSegment(marker byte, payload []byte) bool {
+	switch {
+	case marker == 0xFE: // COM
+		return true
+	case marker == 0xE2: // APP2
+		return !bytes.HasPrefix(payload, []byte("ICC_PROFILE"))
+	case marker == 0xEE: // APP14
+		return !bytes.HasPrefix(payload, []byte("Adobe"))
+	case marker > 0xE0 && marker <= 0xEF: // APP1..APP15
+		return true
+	}
+	return false
+}
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// pngMetadataChunks are ancillary chunks that carry EXIF data or free text
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("invalid png header")
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	i := len(pngSignature)
+	for i+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunkType := string(data[i+4 : i+8])
+		end := i + 12 + length // length + type + data + crc
+		if length < 0 || end > len(data) {
+			return nil, fmt.Errorf("truncated png chunk at offset %d", i)
+		}
+
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out, nil
+}
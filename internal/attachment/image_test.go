@@ -0,0 +1,283 @@
+package attachment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"t-log/internal/config"
+)
+
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		maxW, maxH            int
+		wantWidth, wantHeight int
+	}{
+		{"no limits", 4000, 3000, 0, 0, 4000, 3000},
+		{"already fits", 800, 600, 1920, 1080, 800, 600},
+		{"exactly at limit", 1920, 1080, 1920, 1080, 1920, 1080},
+		{"too wide", 4000, 1000, 2000, 0, 2000, 500},
+		{"too tall", 1000, 4000, 0, 2000, 500, 2000},
+		{"height is the tighter limit", 4000, 3000, 2000, 1000, 1333, 1000},
+		{"width is the tighter limit", 3000, 4000, 1000, 2000, 1000, 1333},
+		{"never upscales", 100, 50, 1000, 1000, 100, 50},
+		{"keeps at least one pixel", 10000, 1, 100, 0, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := fitWithin(tt.width, tt.height, tt.maxW, tt.maxH)
+			if w != tt.wantWidth || h != tt.wantHeight {
+				t.Errorf("fitWithin(%d, %d, %d, %d) = %dx%d, want %dx%d",
+					tt.width, tt.height, tt.maxW, tt.maxH, w, h, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+// halves returns a w x h image, red on the left half and blue on the right
+func halves(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= w/2 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// jpegSegment builds a marker segment with payload
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// exifPayload builds an APP1 Exif block holding only an Orientation tag
+func exifPayload(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)       // One entry
+	order.PutUint16(tiff[10:], 0x0112) // Orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+// withSegments inserts segments right after the SOI marker of a JPEG
+func withSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, data[2:]...)
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	plain := encodeJPEG(t, halves(16, 8))
+	exif := jpegSegment(0xE1, exifPayload(binary.BigEndian, 6))
+	xmp := jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+	comment := jpegSegment(0xFE, []byte("taken at home"))
+	icc := jpegSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01"))
+	adobe := jpegSegment(0xEE, []byte("Adobe\x00\x64\x00\x00\x00\x00\x01"))
+
+	in := withSegments(plain, exif, xmp, comment, icc, adobe)
+	out, err := stripJPEGMetadata(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, seg := range map[string][]byte{"exif": exif, "xmp": xmp, "comment": comment} {
+		if bytes.Contains(out, seg) {
+			t.Errorf("%s segment was kept", name)
+		}
+	}
+	for name, seg := range map[string][]byte{"icc": icc, "adobe": adobe} {
+		if !bytes.Contains(out, seg) {
+			t.Errorf("%s segment was dropped", name)
+		}
+	}
+	if jpegOrientation(out) != 1 {
+		t.Errorf("orientation survived stripping")
+	}
+	if _, _, err := image.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped jpeg doesn't decode: %v", err)
+	}
+
+	if _, err := stripJPEGMetadata([]byte("not a jpeg")); err == nil {
+		t.Errorf("expected an error for a non-jpeg")
+	}
+	if _, err := stripJPEGMetadata(plain[:20]); err == nil {
+		t.Errorf("expected an error for a truncated jpeg")
+	}
+}
+
+// pngChunk builds a chunk with a valid CRC
+func pngChunk(typ string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], typ)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, halves(4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	text := pngChunk("tEXt", []byte("Comment\x00secret"))
+	exif := pngChunk("eXIf", exifPayload(binary.BigEndian, 1)[6:])
+	gamma := pngChunk("gAMA", []byte{0, 0, 0xB1, 0x8F})
+
+	// Ancillary chunks go between IHDR (8 + 25 bytes) and the image data
+	ihdrEnd := len(pngSignature) + 25
+	in := append(append([]byte{}, plain[:ihdrEnd]...), text...)
+	in = append(in, exif...)
+	in = append(in, gamma...)
+	in = append(in, plain[ihdrEnd:]...)
+
+	out, err := stripPNGMetadata(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, text) || bytes.Contains(out, exif) {
+		t.Errorf("metadata chunks were kept")
+	}
+	if !bytes.Contains(out, gamma) {
+		t.Errorf("gAMA chunk was dropped")
+	}
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped png doesn't decode: %v", err)
+	}
+
+	if _, err := stripPNGMetadata([]byte("not a png")); err == nil {
+		t.Errorf("expected an error for a non-png")
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeJPEG(t, halves(16, 8))
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for o := uint16(1); o <= 8; o++ {
+			data := withSegments(plain, jpegSegment(0xE1, exifPayload(order, o)))
+			if got := jpegOrientation(data); got != int(o) {
+				t.Errorf("%v orientation %d: got %d", order, o, got)
+			}
+		}
+	}
+	if got := jpegOrientation(plain); got != 1 {
+		t.Errorf("no exif: got %d, want 1", got)
+	}
+}
+
+// isRed reports whether c is closer to red than to blue (JPEG blurs the edge)
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > b
+}
+
+func TestProcessImageAppliesOrientation(t *testing.T) {
+	// Stored 16x8 with red on the left; orientation 6 means "turn right to view",
+	// which puts the red half on top of an 8x16 image
+	data := withSegments(encodeJPEG(t, halves(16, 8)), jpegSegment(0xE1, exifPayload(binary.BigEndian, 6)))
+
+	tests := []struct {
+		name string
+		cfg  config.ImageConfig
+	}{
+		{"strip only", config.ImageConfig{StripMetadata: true, Quality: 95}},
+		{"resize", config.ImageConfig{MaxWidth: 4, Quality: 95}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := processImage(data, ".jpg", tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if jpegOrientation(out) != 1 {
+				t.Errorf("orientation tag survived")
+			}
+			img, _, err := image.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := img.Bounds()
+			if b.Dx() >= b.Dy() {
+				t.Fatalf("image is %dx%d, want portrait", b.Dx(), b.Dy())
+			}
+			if !isRed(img.At(b.Dx()/2, 1)) || isRed(img.At(b.Dx()/2, b.Dy()-2)) {
+				t.Errorf("image was not turned right")
+			}
+		})
+	}
+
+	// Nothing to re-encode and no stripping: the tag stays and viewers apply it
+	out, _, err := processImage(data, ".jpg", config.ImageConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Errorf("untouched image was rewritten")
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1: red, blue
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	src.Set(1, 0, color.RGBA{0, 0, 255, 255})
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		redAt       image.Point
+	}{
+		{1, 2, 1, image.Pt(0, 0)},
+		{2, 2, 1, image.Pt(1, 0)},
+		{3, 2, 1, image.Pt(1, 0)},
+		{4, 2, 1, image.Pt(0, 0)},
+		{5, 1, 2, image.Pt(0, 0)},
+		{6, 1, 2, image.Pt(0, 0)},
+		{7, 1, 2, image.Pt(0, 1)},
+		{8, 1, 2, image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		dst := applyOrientation(src, tt.orientation)
+		if b := dst.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if !isRed(dst.At(tt.redAt.X, tt.redAt.Y)) {
+			t.Errorf("orientation %d: red pixel not at %v", tt.orientation, tt.redAt)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package attachment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+)
+
+// Import copies srcPath into the Attachment folder next to the daily file
+// for day and returns the link to embed, in linkStyle (config.LinksRelative
+// or absolute). Files are named after their content hash, so importing the
+// same file twice reuses the first copy and yields the same link. With dryRun
+// nothing is written.
+func Import(rootPath string, layout note.Layout, linkStyle, srcPath string, day time.Time, dryRun bool) (string, error) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", srcPath, err)
+	}
+	sum := sha256.Sum256(data)
+	filename := hex.EncodeToString(sum[:4]) + "_" + sanitizeFilename(filepath.Base(srcPath))
+
+	dir := filepath.Join(layout.Dir(rootPath, day), "Attachment")
+	fullPath := filepath.Join(dir, filename)
+
+	if !dryRun && !sameContent(fullPath, data) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create attachment directory: %w", err)
+		}
+		if err := os.WriteFile(fullPath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write attachment: %w", err)
+		}
+	}
+
+	if linkStyle == config.LinksRelative {
+		return relativeLink(filename), nil
+	}
+	relDir, err := filepath.Rel(rootPath, dir)
+	if err != nil {
+		return "", err
+	}
+	return webPath(filepath.ToSlash(relDir), filename), nil
+}
+
+// sameContent reports whether the file at path exists and holds data
+func sameContent(path string, data []byte) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	existing, err := io.ReadAll(f)
+	return err == nil && bytes.Equal(existing, data)
+}
@@ -0,0 +1,280 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/fsutil"
+)
+
+// markdownTargetRegex matches the target of a markdown link or image: [..](target)
+var markdownTargetRegex = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+
+// ConvertReport summarises a link conversion run
+type ConvertReport struct {
+	DryRun       bool           `json:"dryRun"`       // True when no file was written
+	Style        string         `json:"style"`        // Target style (absolute/relative)
+	FilesScanned int            `json:"filesScanned"` // Markdown files examined
+	FilesChanged int            `json:"filesChanged"` // Files containing links to rewrite
+	Links        int            `json:"links"`        // Total links rewritten
+	Changes      map[string]int `json:"changes"`      // Links rewritten per file
+}
+
+// relativeLink is the portable reference from a daily file to an attachment in
+// the same YYYY/MM folder: Attachment/{Filename}
+func relativeLink(filename string) string {
+	return "Attachment/" + url.PathEscape(filename)
+}
+
+// ResolveWebPath maps a request path from the asset server to a file under rootPath.
+// It understands both link styles:
+//   - /attachments/YYYY/MM/Attachment/x.png (absolute style)
+//   - /Attachment/x.png, /MM/Attachment/x.png... which is what the webview
+//     requests when it renders a relative link from the root page
+//
+// It rejects paths that would escape rootPath.
+func ResolveWebPath(rootPath, webPath string) (string, error) {
+	rel := webPath
+	if i := strings.IndexAny(rel, "?#"); i >= 0 {
+		rel = rel[:i]
+	}
+	if unescaped, err := url.PathUnescape(rel); err == nil {
+		rel = unescaped
+	}
+	rel = strings.TrimPrefix(rel, "/attachments/")
+	rel = strings.TrimPrefix(rel, "/")
+
+	fullPath, err := withinRoot(rootPath, rel)
+	if err != nil {
+		return "", fmt.Errorf("attachment path escapes root: %s", webPath)
+	}
+	if _, err := os.Stat(fullPath); err == nil {
+		return fullPath, nil
+	}
+
+	// Relative links resolved against the root page lose (part of) their YYYY/MM
+	// folder, e.g. ../01/Attachment/x.png becomes /01/Attachment/x.png.
+	// Recover the real location from the file name.
+	if i := strings.LastIndex(rel, "Attachment/"); i >= 0 && (i == 0 || rel[i-1] == '/') {
+		if found := findByFilename(rootPath, rel[i+len("Attachment/"):]); found != "" {
+			return found, nil
+		}
+	}
+	return fullPath, nil
+}
+
+// withinRoot joins rel onto rootPath and checks the result stays inside rootPath
+func withinRoot(rootPath, rel string) (string, error) {
+	fullPath := filepath.Join(rootPath, filepath.FromSlash(rel))
+	if !fsutil.Within(rootPath, fullPath) {
+		return "", fmt.Errorf("path escapes root: %s", rel)
+	}
+	return fullPath, nil
+}
+
+// findByFilename locates an attachment from its name alone. Names start with the
+// millisecond timestamp they were saved at, which gives the YYYY/MM folder;
+// otherwise every Attachment folder the notebook layouts can produce is searched.
+func findByFilename(rootPath, name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+
+	if prefix, _, ok := strings.Cut(name, "_"); ok {
+		prefix, _, _ = strings.Cut(prefix, "-") // Counter of same-millisecond names
+		if millis, err := strconv.ParseInt(prefix, 10, 64); err == nil {
+			t := time.UnixMilli(millis)
+			candidate := filepath.Join(rootPath, t.Format("2006"), t.Format("01"), "Attachment", name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+		}
+	}
+
+	// Monthly, yearly and flat notebook layouts
+	for _, pattern := range []string{
+		filepath.Join(rootPath, "*", "*", "Attachment", name),
+		filepath.Join(rootPath, "*", "Attachment", name),
+		filepath.Join(rootPath, "Attachment", name),
+	} {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return matches[0]
+		}
+	}
+	return ""
+}
+
+// relativeTarget resolves a relative markdown link target found in the note at
+// notePath. It returns the attachment path relative to rootPath (slash separated)
+// or "" when the target is not a local attachment.
+func relativeTarget(rootPath, notePath, target string) string {
+	if target == "" || strings.HasPrefix(target, "/") || strings.HasPrefix(target, "#") ||
+		strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return ""
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+
+	full := filepath.Join(filepath.Dir(notePath), filepath.FromSlash(target))
+	rel, err := filepath.Rel(rootPath, full)
+	if err != nil || !fsutil.Within(rootPath, full) {
+		return ""
+	}
+	if filepath.Base(filepath.Dir(full)) != "Attachment" {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ResolveLink maps a link target found in the note at notePath to the
+// attachment file it points to, for both /attachments/... and relative links.
+// It returns "" for remote links, links outside rootPath and missing files.
+func ResolveLink(rootPath, notePath, target string) string {
+	var path string
+	if strings.HasPrefix(target, "/attachments/") {
+		p, err := ResolveWebPath(rootPath, target)
+		if err != nil {
+			return ""
+		}
+		path = p
+	} else {
+		rel := relativeTarget(rootPath, notePath, target)
+		if rel == "" {
+			return ""
+		}
+		path = filepath.Join(rootPath, filepath.FromSlash(rel))
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// ConvertLinks rewrites attachment links in every note under rootPath to the
+// given style. With dryRun set it only reports what would change.
+func ConvertLinks(rootPath, style string, dryRun bool) (*ConvertReport, error) {
+	if style != config.LinksAbsolute && style != config.LinksRelative {
+		return nil, fmt.Errorf("unknown link style: %s", style)
+	}
+
+	report := &ConvertReport{DryRun: dryRun, Style: style, Changes: map[string]int{}}
+
+	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == TrashDirName || d.Name() == "Attachment" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+		report.FilesScanned++
+
+		// Locked so a note saved meanwhile is not overwritten by the converted copy
+		f, err := fsutil.OpenLocked(path, os.O_RDWR, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer f.Close()
+
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		converted, count := convertContent(rootPath, path, string(content), style)
+		if count == 0 {
+			return nil
+		}
+		report.FilesChanged++
+		report.Links += count
+		report.Changes[path] = count
+
+		if dryRun {
+			return nil
+		}
+		// Written in place like note.RemoveEntry (writers waiting on the lock
+		// keep a valid handle), content first and truncated after, so a failed
+		// write never leaves an empty note
+		if _, err := f.WriteAt([]byte(converted), 0); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := f.Truncate(int64(len(converted))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return f.Sync()
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// convertContent rewrites the markdown link targets in one note
+func convertContent(rootPath, notePath, content, style string) (string, int) {
+	count := 0
+
+	converted := markdownTargetRegex.ReplaceAllStringFunc(content, func(match string) string {
+		target := match[2 : len(match)-1]
+
+		var rel string
+		if strings.HasPrefix(target, "/attachments/") {
+			if style == config.LinksAbsolute {
+				return match
+			}
+			full, err := ResolveWebPath(rootPath, target)
+			if err != nil {
+				return match
+			}
+			r, err := filepath.Rel(rootPath, full)
+			if err != nil {
+				return match
+			}
+			rel = filepath.ToSlash(r)
+		} else {
+			if style == config.LinksRelative {
+				return match
+			}
+			if rel = relativeTarget(rootPath, notePath, target); rel == "" {
+				return match
+			}
+		}
+
+		var newTarget string
+		if style == config.LinksAbsolute {
+			newTarget = "/attachments/" + escapePath(rel)
+		} else {
+			fromNote, err := filepath.Rel(filepath.Dir(notePath), filepath.Join(rootPath, filepath.FromSlash(rel)))
+			if err != nil {
+				return match
+			}
+			newTarget = escapePath(filepath.ToSlash(fromNote))
+		}
+
+		if newTarget == target {
+			return match
+		}
+		count++
+		return "](" + newTarget + ")"
+	})
+
+	return converted, count
+}
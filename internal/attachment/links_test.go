@@ -0,0 +1,71 @@
+package attachment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"t-log/internal/config"
+)
+
+func TestConvertLinks(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2025", "06")
+	if err := os.MkdirAll(filepath.Join(dir, "Attachment"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Attachment", "a.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notePath := filepath.Join(dir, "2025-06-30.md")
+	absolute := "- [09:00] ![](/attachments/2025/06/Attachment/a.png)\n- [10:00] [site](https://example.com/a.png)\n"
+	relative := "- [09:00] ![](Attachment/a.png)\n- [10:00] [site](https://example.com/a.png)\n"
+	if err := os.WriteFile(notePath, []byte(absolute), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(notePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	report, err := ConvertLinks(root, config.LinksRelative, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FilesChanged != 1 || report.Links != 1 || read() != absolute {
+		t.Errorf("dry run: %+v, file %q", report, read())
+	}
+
+	// Shorter links: the old tail must not survive
+	if _, err := ConvertLinks(root, config.LinksRelative, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != relative {
+		t.Errorf("relative:\n got %q\nwant %q", got, relative)
+	}
+
+	// Converting again changes nothing
+	report, err = ConvertLinks(root, config.LinksRelative, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FilesChanged != 0 {
+		t.Errorf("second conversion changed %d files", report.FilesChanged)
+	}
+
+	if _, err := ConvertLinks(root, config.LinksAbsolute, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != absolute {
+		t.Errorf("absolute:\n got %q\nwant %q", got, absolute)
+	}
+
+	if _, err := ConvertLinks(root, "other", false); err == nil {
+		t.Errorf("expected an error for an unknown style")
+	}
+}
@@ -6,13 +6,19 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"t-log/internal/config"
+	"t-log/internal/note"
 )
 
 type Manager struct {
-	config *config.AppConfig
+	mu       sync.RWMutex
+	config   *config.AppConfig
+	notebook string          // Notebook new attachments are saved to ("" = active)
+	pinned   func() []string // Attachments in use outside the notes (drafts), never collected
 }
 
 func NewManager(cfg *config.AppConfig) *Manager {
@@ -21,14 +27,59 @@ func NewManager(cfg *config.AppConfig) *Manager {
 	}
 }
 
+// SetConfig points the manager at a new configuration (e.g. a changed RootPath)
+func (m *Manager) SetConfig(cfg *config.AppConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = cfg
+}
+
+// SetNotebook sends new attachments to the named notebook instead of the
+// active one, so links resolve from the daily file the note is saved to
+func (m *Manager) SetNotebook(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notebook = name
+}
+
+// currentNotebook is the notebook new attachments are saved to
+func (m *Manager) currentNotebook() config.Notebook {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if nb, ok := m.config.FindNotebook(m.notebook); ok && m.notebook != "" {
+		return nb
+	}
+	return m.config.Active()
+}
+
+// SetPinned registers a source of attachment files that garbage collection
+// must keep even though no note links to them yet
+func (m *Manager) SetPinned(pinned func() []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pinned = pinned
+}
+
+func (m *Manager) pinnedFiles() []string {
+	m.mu.RLock()
+	pinned := m.pinned
+	m.mu.RUnlock()
+	if pinned == nil {
+		return nil
+	}
+	return pinned()
+}
+
+func (m *Manager) currentConfig() *config.AppConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
+}
+
 // EnsureDir creates the attachment directory for the current month if it doesn't exist
 func (m *Manager) EnsureDir() (string, error) {
-	now := time.Now()
-	year := now.Format("2006")
-	month := now.Format("01")
-
-	// {RootPath}/{YYYY}/{MM}/Attachment/
-	path := filepath.Join(m.config.RootPath, year, month, "Attachment")
+	// {RootPath}/{YYYY}/{MM}/Attachment/ for the monthly layout
+	path := m.attachmentDir(time.Now())
 
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return "", fmt.Errorf("failed to create attachment directory: %w", err)
@@ -37,43 +88,159 @@ func (m *Manager) EnsureDir() (string, error) {
 	return path, nil
 }
 
+// attachmentDir is the Attachment folder next to the target notebook's daily
+// file for now, so relative links from that file stay short
+func (m *Manager) attachmentDir(now time.Time) string {
+	return notebookAttachmentDir(m.currentNotebook(), now)
+}
+
+// notebookAttachmentDir is the Attachment folder next to nb's daily file for now
+func notebookAttachmentDir(nb config.Notebook, now time.Time) string {
+	return filepath.Join(note.Layout(nb.Layout).Dir(nb.RootPath, now), "Attachment")
+}
+
 func sanitizeFilename(name string) string {
 	// Replace illegal characters with _
 	re := regexp.MustCompile(`[\\/:*?"<>|]`)
 	return re.ReplaceAllString(name, "_")
 }
 
-// SaveAttachment saves the content to a file and returns the web-accessible path
+// SaveAttachment saves the content to a file and returns the path to embed in the note.
+// Depending on AttachmentLinks this is /attachments/... or relative to the daily file.
 func (m *Manager) SaveAttachment(content []byte, filename string) (string, error) {
+	_, link, err := m.saveContent(content, filename)
+	return link, err
+}
+
+// saveContent stores content as a new attachment and returns its physical path and link
+func (m *Manager) saveContent(content []byte, filename string) (string, string, error) {
 	dir, err := m.EnsureDir()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	// Generate unique filename: {Timestamp}_{OriginalName}
-	timestamp := time.Now().UnixMilli()
 	sanitized := sanitizeFilename(filename)
-	newFilename := fmt.Sprintf("%d_%s", timestamp, sanitized)
-	fullPath := filepath.Join(dir, newFilename)
+
+	// Downscale, re-encode and strip metadata according to the image settings
+	if m.currentConfig().Image.Enabled {
+		ext := filepath.Ext(sanitized)
+		processed, newExt, err := processImage(content, ext, m.currentConfig().Image)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to process image: %w", err)
+		}
+		content = processed
+		sanitized = strings.TrimSuffix(sanitized, ext) + newExt
+	}
+
+	// Generate unique filename: {Timestamp}_{OriginalName}
+	now := time.Now()
+	f, newFilename, err := createUnique(dir, now, sanitized)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create attachment: %w", err)
+	}
+	fullPath := f.Name()
 
 	// Write file
-	if err := os.WriteFile(fullPath, content, 0644); err != nil {
-		return "", fmt.Errorf("failed to write attachment: %w", err)
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(fullPath)
+		return "", "", fmt.Errorf("failed to write attachment: %w", err)
 	}
 
-	// Return path relative to RootPath/.. -> Web Path
-	// The web handler will handle /attachments/ prefix and map it to RootPath
-	// We return: /attachments/{YYYY}/{MM}/Attachment/{Filename}
+	// Thumbnails are best-effort: a failure here must not lose the attachment itself
+	if m.currentConfig().Image.ThumbnailSize > 0 {
+		if err := m.saveThumbnail(dir, newFilename, content); err != nil {
+			fmt.Printf("Failed to generate thumbnail for %s: %v\n", newFilename, err)
+		}
+	}
 
-	now := time.Now()
-	year := now.Format("2006")
-	month := now.Format("01")
+	return fullPath, m.link(now, newFilename), nil
+}
+
+// link returns the reference to embed for a file saved at time now,
+// honouring the configured AttachmentLinks style
+func (m *Manager) link(now time.Time, filename string) string {
+	return m.linkIn(m.currentNotebook(), now, filename)
+}
+
+// linkIn is link for a file saved in nb's Attachment folder for now
+func (m *Manager) linkIn(nb config.Notebook, now time.Time, filename string) string {
+	if m.currentConfig().AttachmentLinks == config.LinksRelative {
+		// Today's daily file lives in the same folder as the Attachment dir
+		return relativeLink(filename)
+	}
+
+	relDir, err := filepath.Rel(nb.RootPath, notebookAttachmentDir(nb, now))
+	if err != nil {
+		relDir = filepath.Join(now.Format("2006"), now.Format("01"), "Attachment")
+	}
+	return webPath(filepath.ToSlash(relDir), filename)
+}
 
+// maxNameAttempts bounds how many suffixes createUnique tries
+const maxNameAttempts = 1000
+
+// uniqueFilename prefixes the sanitized name with a millisecond timestamp.
+// n > 0 adds a counter for files saved in the same millisecond.
+func uniqueFilename(now time.Time, sanitized string, n int) string {
+	if n > 0 {
+		return fmt.Sprintf("%d-%d_%s", now.UnixMilli(), n, sanitized)
+	}
+	return fmt.Sprintf("%d_%s", now.UnixMilli(), sanitized)
+}
+
+// createUnique creates a new file in dir named by uniqueFilename, counting up
+// while the name is taken. Existing files are never overwritten.
+func createUnique(dir string, now time.Time, sanitized string) (*os.File, string, error) {
+	for n := 0; n < maxNameAttempts; n++ {
+		name := uniqueFilename(now, sanitized, n)
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			return f, name, nil
+		}
+		if !os.IsExist(err) {
+			return nil, "", err
+		}
+	}
+	return nil, "", fmt.Errorf("no free name for %s", sanitized)
+}
+
+// webPath builds the URL the asset handler serves a stored attachment from.
+// relDir is the Attachment folder relative to the notebook root; the web
+// handler maps the /attachments/ prefix back to it:
+// /attachments/{YYYY}/{MM}/Attachment/{Filename}
+func webPath(relDir, filename string) string {
 	// URL Encode the filename part to handle spaces and special chars in URL
-	encodedFilename := url.PathEscape(newFilename)
+	encodedFilename := url.PathEscape(filename)
 
 	// Using forward slashes for web URL
-	webPath := fmt.Sprintf("/attachments/%s/%s/Attachment/%s", year, month, encodedFilename)
+	return fmt.Sprintf("/attachments/%s/%s", escapePath(relDir), encodedFilename)
+}
+
+// saveThumbnail writes a JPEG preview to Attachment/.thumbs/{filename}.jpg
+func (m *Manager) saveThumbnail(dir, filename string, content []byte) error {
+	data, ok, err := generateThumbnail(content, m.currentConfig().Image.ThumbnailSize)
+	if err != nil || !ok {
+		return err
+	}
 
-	return webPath, nil
+	thumbDir := filepath.Join(dir, ThumbDirName)
+	if err := os.MkdirAll(thumbDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(thumbDir, filename+".jpg"), data, 0644)
+}
+
+// ThumbnailFile returns the physical thumbnail path for an attachment file,
+// or "" when no thumbnail has been generated for it.
+func ThumbnailFile(attachmentPath string) string {
+	dir, name := filepath.Split(attachmentPath)
+	thumb := filepath.Join(dir, ThumbDirName, name+".jpg")
+	if _, err := os.Stat(thumb); err != nil {
+		return ""
+	}
+	return thumb
 }
@@ -0,0 +1,89 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+)
+
+// Relocate copies the attachments that content links to from the notebook
+// new attachments were saved to into nb, and points the links at the copies.
+// It is needed when a note ends up in another notebook than the one its
+// attachments were pasted into (an @name prefix). The originals are left in
+// place; once nothing links to them, garbage collection removes them.
+// Links that can't be resolved are kept as they are.
+func (m *Manager) Relocate(content string, nb config.Notebook, now time.Time) (string, error) {
+	src := m.currentNotebook()
+	if src.RootPath == nb.RootPath && src.Layout == nb.Layout {
+		return content, nil
+	}
+	srcNote := note.Layout(src.Layout).DailyFile(src.RootPath, now)
+	dir := notebookAttachmentDir(nb, now)
+
+	copied := map[string]string{} // Source file -> new link
+	var firstErr error
+	relocated := markdownTargetRegex.ReplaceAllStringFunc(content, func(match string) string {
+		path := ResolveLink(src.RootPath, srcNote, match[2:len(match)-1])
+		if path == "" {
+			return match
+		}
+		link, ok := copied[path]
+		if !ok {
+			name, err := copyAttachment(path, dir, now)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return match
+			}
+			link = m.linkIn(nb, now, name)
+			copied[path] = link
+		}
+		return "](" + link + ")"
+	})
+	return relocated, firstErr
+}
+
+// copyAttachment copies the attachment at path, and its thumbnail, into dir.
+// It keeps the file name unless dir already has a file by that name.
+func copyAttachment(path, dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attachment: %w", err)
+	}
+	defer in.Close()
+
+	name := filepath.Base(path)
+	out, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		out, name, err = createUnique(dir, now, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("failed to copy attachment: %w", err)
+	}
+
+	// Best-effort, like generating it
+	if thumb := ThumbnailFile(path); thumb != "" {
+		if data, err := os.ReadFile(thumb); err == nil && os.MkdirAll(filepath.Join(dir, ThumbDirName), 0755) == nil {
+			os.WriteFile(filepath.Join(dir, ThumbDirName, name+".jpg"), data, 0644)
+		}
+	}
+	return name, nil
+}
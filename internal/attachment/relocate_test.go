@@ -0,0 +1,61 @@
+package attachment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+)
+
+func TestRelocate(t *testing.T) {
+	for _, style := range []string{config.LinksRelative, config.LinksAbsolute} {
+		t.Run(style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.RootPath = t.TempDir()
+			cfg.AttachmentLinks = style
+			cfg.Image.Enabled = false
+			cfg.Image.ThumbnailSize = 0
+			work := config.Notebook{Name: "work", RootPath: t.TempDir(), Layout: string(note.LayoutFlat)}
+			cfg.Notebooks = []config.Notebook{work}
+
+			// Pasted while the window was open for the default notebook
+			m := NewManager(cfg)
+			link, err := m.SaveAttachment([]byte("pdf"), "plan.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := "see [plan](" + link + ") and [again](" + link + ") [site](https://example.com/x.pdf)"
+
+			now := time.Now()
+			got, err := m.Relocate(content, work, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, "(https://example.com/x.pdf)") {
+				t.Errorf("remote link changed: %s", got)
+			}
+
+			// Every rewritten link resolves inside the work notebook
+			workNote := note.LayoutFlat.DailyFile(work.RootPath, now)
+			refs := References(work.RootPath, workNote, got)
+			if len(refs) != 1 {
+				t.Fatalf("work note references %v", refs)
+			}
+			if data, err := os.ReadFile(refs[0]); err != nil || string(data) != "pdf" {
+				t.Errorf("copy = %q, %v", data, err)
+			}
+			if dir := filepath.Dir(refs[0]); dir != filepath.Join(work.RootPath, "Attachment") {
+				t.Errorf("copied to %s", dir)
+			}
+
+			// Same notebook: nothing to do
+			if same, err := m.Relocate(content, cfg.Active(), now); err != nil || same != content {
+				t.Errorf("same notebook: %q, %v", same, err)
+			}
+		})
+	}
+}
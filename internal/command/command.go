@@ -2,53 +2,339 @@ package command
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Command represents an executable action in the palette
 type Command struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Usage       string `json:"usage"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Usage       string   `json:"usage"`
+	Aliases     []string `json:"aliases"` // Extra words the palette matches, e.g. "grep" for Find
+	Params      []Param  `json:"params"`  // Positional arguments, prompted for by the palette
 }
 
 // Handler is the function that executes the command
-type Handler func(args []string) error
+type Handler func(args Args) error
 
-// CommandRegistry manages available commands
+// CommandRegistry manages available commands. It is safe for concurrent use:
+// user commands are re-registered from a file watcher while the palette reads.
 type CommandRegistry struct {
-	commands map[string]Command
-	handlers map[string]Handler
+	mu             sync.RWMutex
+	commands       map[string]Command
+	handlers       map[string]Handler
+	typeProviders  map[ParamType]Provider
+	paramProviders map[string]Provider // Keyed by "commandID/paramName"
+	frecency       *Frecency           // Usage history for ranking (optional)
 }
 
 // NewRegistry creates a new command registry
 func NewRegistry() *CommandRegistry {
 	return &CommandRegistry{
-		commands: make(map[string]Command),
-		handlers: make(map[string]Handler),
+		commands:       make(map[string]Command),
+		handlers:       make(map[string]Handler),
+		typeProviders:  map[ParamType]Provider{ParamFile: completeFile, ParamPath: completePath},
+		paramProviders: make(map[string]Provider),
 	}
 }
 
 // Register adds a command to the registry
 func (r *CommandRegistry) Register(cmd Command, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.commands[cmd.ID] = cmd
 	r.handlers[cmd.ID] = handler
 }
 
-// GetCommands returns all registered commands
+// SetTypeProvider supplies completions for every parameter of type t
+// (e.g. the configured notebooks for ParamNotebook)
+func (r *CommandRegistry) SetTypeProvider(t ParamType, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.typeProviders[t] = p
+}
+
+// SetParamProvider supplies completions for one command's parameter,
+// overriding the provider for its type
+func (r *CommandRegistry) SetParamProvider(id, param string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paramProviders[id+"/"+param] = p
+}
+
+// Unregister removes a command, e.g. a user command that was deleted from commands.json
+func (r *CommandRegistry) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.commands, id)
+	delete(r.handlers, id)
+	for key := range r.paramProviders {
+		if strings.HasPrefix(key, id+"/") {
+			delete(r.paramProviders, key)
+		}
+	}
+}
+
+// SetFrecency enables usage-based ranking; every successful Execute is recorded
+func (r *CommandRegistry) SetFrecency(f *Frecency) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frecency = f
+}
+
+// GetCommands returns all registered commands sorted by title
 func (r *CommandRegistry) GetCommands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	cmds := make([]Command, 0, len(r.commands))
 	for _, cmd := range r.commands {
 		cmds = append(cmds, cmd)
 	}
+	sort.Slice(cmds, func(i, j int) bool {
+		if cmds[i].Title != cmds[j].Title {
+			return cmds[i].Title < cmds[j].Title
+		}
+		return cmds[i].ID < cmds[j].ID
+	})
 	return cmds
 }
 
-// Execute runs a command by ID
+// Search returns the commands matching query, best first. Title, aliases, ID
+// and description are matched fuzzily; among similar matches, commands used
+// often and recently come first. An empty query lists every command by
+// frecency, then title.
+func (r *CommandRegistry) Search(query string) []Command {
+	query = strings.TrimSpace(query)
+	now := time.Now()
+
+	type ranked struct {
+		cmd   Command
+		score float64
+	}
+	var results []ranked
+
+	r.mu.RLock()
+	frecency := r.frecency
+	r.mu.RUnlock()
+
+	// GetCommands' title order makes ties deterministic under the stable sort
+	for _, cmd := range r.GetCommands() {
+		match, ok := matchCommand(cmd, query)
+		if !ok {
+			continue
+		}
+		score := float64(match)
+		if frecency != nil {
+			// Logarithmic so heavy use breaks ties without burying a much better match
+			score += 15 * math.Log1p(frecency.Score(cmd.ID, now))
+		}
+		results = append(results, ranked{cmd, score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	cmds := make([]Command, len(results))
+	for i, res := range results {
+		cmds[i] = res.cmd
+	}
+	return cmds
+}
+
+// matchCommand returns the best fuzzy score of query against the command's
+// searchable fields. Description matches count for less.
+func matchCommand(cmd Command, query string) (int, bool) {
+	best, found := 0, false
+	consider := func(text string, weight int) {
+		if score, ok := fuzzyScore(query, text); ok && (!found || score/weight > best) {
+			best, found = score/weight, true
+		}
+	}
+
+	consider(cmd.Title, 1)
+	consider(strings.TrimPrefix(cmd.ID, "cmd:"), 1)
+	for _, alias := range cmd.Aliases {
+		consider(alias, 1)
+	}
+	if cmd.Description != "" {
+		consider(cmd.Description, 2)
+	}
+	return best, found
+}
+
+// Complete returns suggestions for a parameter of a command
+func (r *CommandRegistry) Complete(id, param, prefix string) ([]Completion, error) {
+	provider, err := r.provider(id, param)
+	if err != nil {
+		return nil, err
+	}
+	// Providers may be slow (search), so they run without holding the lock
+	return provider(prefix), nil
+}
+
+// provider picks the completion source for a parameter: a command-specific
+// provider, then the one for its type, then the enum options
+func (r *CommandRegistry) provider(id, param string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, ok := r.commands[id]
+	if !ok {
+		return nil, fmt.Errorf("command not found: %s", id)
+	}
+	for _, p := range cmd.Params {
+		if p.Name != param {
+			continue
+		}
+		if provider, ok := r.paramProviders[id+"/"+p.Name]; ok {
+			return provider, nil
+		}
+		if provider, ok := r.typeProviders[p.Type]; ok {
+			return provider, nil
+		}
+		options := p.Options
+		return func(prefix string) []Completion {
+			completions := []Completion{}
+			for _, opt := range options {
+				if strings.HasPrefix(opt, prefix) {
+					completions = append(completions, Completion{Value: opt, Label: opt})
+				}
+			}
+			return completions
+		}, nil
+	}
+	return nil, fmt.Errorf("command %s has no parameter %s", id, param)
+}
+
+// Execute runs a command by ID. args are positional and matched to the
+// command's Params; missing ones take their default.
 func (r *CommandRegistry) Execute(id string, args []string) error {
+	return r.ExecuteWith(id, args, nil)
+}
+
+// ExecuteWith is Execute with extra context values (such as the editor
+// "selection") that the caller knows but are not parameters. They are added
+// to Args unless a parameter of the same name was bound.
+func (r *CommandRegistry) ExecuteWith(id string, args []string, env map[string]string) error {
+	r.mu.RLock()
 	handler, ok := r.handlers[id]
+	cmd := r.commands[id]
+	frecency := r.frecency
+	validate := make(map[ParamType]Provider, len(r.typeProviders))
+	for t, p := range r.typeProviders {
+		validate[t] = p
+	}
+	r.mu.RUnlock()
+
 	if !ok {
 		return fmt.Errorf("command not found: %s", id)
 	}
-	return handler(args)
+	bound, err := bind(cmd, args, validate)
+	if err != nil {
+		return err
+	}
+	for k, v := range env {
+		if _, ok := bound[k]; !ok {
+			bound[k] = v
+		}
+	}
+	if err := handler(bound); err != nil {
+		return err
+	}
+
+	if frecency != nil {
+		if err := frecency.Record(id); err != nil {
+			fmt.Printf("Error saving command usage: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// bind matches positional values to parameters, applying defaults and validation.
+// typeProviders supply the valid values for types such as ParamNotebook.
+func bind(cmd Command, values []string, typeProviders map[ParamType]Provider) (Args, error) {
+	if len(values) > len(cmd.Params) {
+		return nil, fmt.Errorf("%s: too many arguments (want at most %d)", cmd.ID, len(cmd.Params))
+	}
+
+	args := Args{}
+	for i, p := range cmd.Params {
+		value := p.Default
+		if i < len(values) && values[i] != "" {
+			value = values[i]
+		}
+		if value == "" {
+			if p.Required {
+				return nil, fmt.Errorf("%s: missing argument %s", cmd.ID, p.Name)
+			}
+			continue
+		}
+
+		normalized, err := p.normalize(value, typeProviders[p.Type])
+		if err != nil {
+			return nil, err
+		}
+		args[p.Name] = normalized
+	}
+	return args, nil
+}
+
+// completePath is completeFile, offering the typed folder itself first so
+// it can be chosen rather than descended into
+func completePath(prefix string) []Completion {
+	completions := completeFile(prefix)
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) && !strings.HasSuffix(prefix, "/") {
+		return completions
+	}
+	if info, err := os.Stat(prefix); err == nil && info.IsDir() {
+		folder := strings.TrimRight(prefix, `/\`)
+		if folder == "" {
+			folder = prefix
+		}
+		completions = append([]Completion{{Value: folder, Label: ". (this folder)", Description: folder}}, completions...)
+	}
+	return completions
+}
+
+// completeFile lists files and folders matching a partially typed path
+func completeFile(prefix string) []Completion {
+	dir, base := filepath.Split(prefix)
+	if dir == "" {
+		var err error
+		if dir, err = os.UserHomeDir(); err != nil {
+			return []Completion{}
+		}
+		dir += string(filepath.Separator)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []Completion{}
+	}
+
+	completions := []Completion{}
+	for _, e := range entries {
+		if !strings.HasPrefix(strings.ToLower(e.Name()), strings.ToLower(base)) {
+			continue
+		}
+		value := filepath.Join(dir, e.Name())
+		label := e.Name()
+		if e.IsDir() {
+			value += string(filepath.Separator)
+			label += string(filepath.Separator)
+		}
+		completions = append(completions, Completion{Value: value, Label: label, Description: dir})
+		if len(completions) >= 50 {
+			break
+		}
+	}
+	return completions
 }
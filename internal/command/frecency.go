@@ -0,0 +1,94 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+// Usage records how often and how recently a command was run
+type Usage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Frecency ranks commands by how frequently and recently they were used.
+// Usage is persisted to a JSON file so the ranking survives restarts.
+type Frecency struct {
+	path string
+
+	saveMu sync.Mutex // Held across a save, so an older snapshot never overwrites a newer one
+	mu     sync.Mutex
+	usage  map[string]Usage
+}
+
+// LoadFrecency reads usage data from path. A missing or unreadable file
+// starts an empty history rather than failing.
+func LoadFrecency(path string) *Frecency {
+	f := &Frecency{path: path, usage: map[string]Usage{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error reading command usage: %v\n", err)
+		}
+		return f
+	}
+	if err := json.Unmarshal(data, &f.usage); err != nil {
+		fmt.Printf("Error parsing command usage: %v\n", err)
+		f.usage = map[string]Usage{}
+	}
+	return f
+}
+
+// Record counts one run of the command and saves the history
+func (f *Frecency) Record(id string) error {
+	// Taken before the snapshot: whoever saves last also snapshotted last.
+	// Score only needs mu, so ranking doesn't wait for the disk.
+	f.saveMu.Lock()
+	defer f.saveMu.Unlock()
+
+	f.mu.Lock()
+	u := f.usage[id]
+	u.Count++
+	u.LastUsed = time.Now()
+	f.usage[id] = u
+	data, err := json.MarshalIndent(f.usage, "", "  ")
+	f.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(f.path, data, 0644)
+}
+
+// Score weights the use count by how long ago the command was last used,
+// so a command used a lot last year drops below one used a few times today
+func (f *Frecency) Score(id string, now time.Time) float64 {
+	f.mu.Lock()
+	u, ok := f.usage[id]
+	f.mu.Unlock()
+	if !ok {
+		return 0
+	}
+
+	age := now.Sub(u.LastUsed)
+	var weight float64
+	switch {
+	case age < 4*24*time.Hour:
+		weight = 100
+	case age < 14*24*time.Hour:
+		weight = 70
+	case age < 31*24*time.Hour:
+		weight = 50
+	case age < 90*24*time.Hour:
+		weight = 30
+	default:
+		weight = 10
+	}
+	return float64(u.Count) * weight
+}
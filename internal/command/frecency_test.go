@@ -0,0 +1,110 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFrecencyScoreDecay(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		count int
+		age   time.Duration
+		want  float64
+	}{
+		{"used today", 3, time.Hour, 300},
+		{"just under 4 days", 3, 4*day - time.Minute, 300},
+		{"4 days", 3, 4 * day, 210},
+		{"two weeks", 3, 14 * day, 150},
+		{"a month", 3, 31 * day, 90},
+		{"a quarter", 3, 90 * day, 30},
+		{"last year", 3, 365 * day, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Frecency{usage: map[string]Usage{"cmd:x": {Count: tt.count, LastUsed: now.Add(-tt.age)}}}
+			if got := f.Score("cmd:x", now); got != tt.want {
+				t.Errorf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	f := &Frecency{usage: map[string]Usage{}}
+	if got := f.Score("cmd:never", now); got != 0 {
+		t.Errorf("unused command scores %v, want 0", got)
+	}
+
+	// Heavy use long ago ranks below a little use today
+	f.usage["old"] = Usage{Count: 20, LastUsed: now.Add(-200 * day)}
+	f.usage["new"] = Usage{Count: 3, LastUsed: now.Add(-time.Hour)}
+	if f.Score("old", now) >= f.Score("new", now) {
+		t.Errorf("old %v >= new %v", f.Score("old", now), f.Score("new", now))
+	}
+}
+
+func TestFrecencyPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command-usage.json")
+	f := LoadFrecency(path)
+	for i := 0; i < 2; i++ {
+		if err := f.Record("cmd:a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := LoadFrecency(path)
+	if u := loaded.usage["cmd:a"]; u.Count != 2 || u.LastUsed.IsZero() {
+		t.Errorf("reloaded usage = %+v", u)
+	}
+
+	if err := os.WriteFile(path, []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if broken := LoadFrecency(path); len(broken.usage) != 0 {
+		t.Errorf("corrupt file gave usage %v", broken.usage)
+	}
+}
+
+func TestFrecencyConcurrentRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "command-usage.json")
+	f := LoadFrecency(path)
+
+	const workers, runs = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < runs; i++ {
+				if err := f.Record(fmt.Sprintf("cmd:%d", w%2)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// The file must hold the final counts, not an older snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usage map[string]Usage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, u := range usage {
+		total += u.Count
+	}
+	if total != workers*runs {
+		t.Errorf("saved %d runs, want %d", total, workers*runs)
+	}
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyScore matches query against text as a case-insensitive subsequence.
+// Consecutive characters, matches at word starts and a match at the very
+// beginning score higher. ok is false when query is not a subsequence of text.
+func fuzzyScore(query, text string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	qi := 0
+	prevMatch := -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+
+		score++
+		if ti == prevMatch+1 {
+			score += 5 // Consecutive characters
+		}
+		if ti == 0 {
+			score += 10 // Start of text
+		} else if !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 8 // Start of a word
+		}
+		prevMatch = ti
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter texts when the same characters matched
+	return score*10 - len(t), true
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		ok          bool
+	}{
+		{"", "Anything", true},
+		{"exp", "Export Notes", true},
+		{"EXP", "export notes", true},
+		{"en", "Export Notes", true},
+		{"xn", "Export Notes", true},
+		{"nx", "Export Notes", false},
+		{"exports", "Export", false},
+		{"报告", "生成报告", true},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.query, tt.text); ok != tt.ok {
+			t.Errorf("fuzzyScore(%q, %q) ok = %v, want %v", tt.query, tt.text, ok, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	// Each pair: the query should score higher against better than worse
+	tests := []struct {
+		name          string
+		query         string
+		better, worse string
+	}{
+		{"prefix beats middle", "open", "Open Date", "Reopen Date"},
+		{"consecutive beats scattered", "tag", "Stage", "Strange"},
+		{"word starts beat inner letters", "gr", "Generate Report", "Program"},
+		{"shorter text on equal match", "site", "Site", "Site Builder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, okB := fuzzyScore(tt.query, tt.better)
+			w, okW := fuzzyScore(tt.query, tt.worse)
+			if !okB || !okW {
+				t.Fatalf("no match: %v %v", okB, okW)
+			}
+			if b <= w {
+				t.Errorf("%q: %q scored %d, %q scored %d", tt.query, tt.better, b, tt.worse, w)
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	r := NewRegistry()
+	noop := func(Args) error { return nil }
+	r.Register(Command{ID: "cmd:export", Title: "Export Notes", Aliases: []string{"save"}}, noop)
+	r.Register(Command{ID: "cmd:exit", Title: "Exit"}, noop)
+	r.Register(Command{ID: "cmd:search", Title: "Search Notes", Description: "Find text in every export"}, noop)
+	r.Register(Command{ID: "cmd:stats", Title: "Show Statistics"}, noop)
+
+	ids := func(cmds []Command) []string {
+		out := make([]string, len(cmds))
+		for i, c := range cmds {
+			out[i] = c.ID
+		}
+		return out
+	}
+
+	got := ids(r.Search("exp"))
+	if len(got) != 2 || got[0] != "cmd:export" || got[1] != "cmd:search" {
+		t.Errorf("Search(exp) = %v, want export then search (description match)", got)
+	}
+	if got := ids(r.Search("save")); len(got) != 1 || got[0] != "cmd:export" {
+		t.Errorf("Search(save) = %v, want the alias match", got)
+	}
+	if got := r.Search("zzz"); len(got) != 0 {
+		t.Errorf("Search(zzz) = %v", ids(got))
+	}
+
+	// Without history an empty query lists by title
+	if got := ids(r.Search("")); got[0] != "cmd:exit" {
+		t.Errorf("Search() = %v, want title order", got)
+	}
+
+	// Recent heavy use lifts a command among similar matches
+	f := &Frecency{usage: map[string]Usage{"cmd:exit": {Count: 50, LastUsed: time.Now()}}}
+	r.SetFrecency(f)
+	if got := ids(r.Search("ex")); got[0] != "cmd:exit" {
+		t.Errorf("Search(ex) with history = %v, want exit first", got)
+	}
+	f.usage["cmd:stats"] = Usage{Count: 50, LastUsed: time.Now()}
+	if got := ids(r.Search("")); got[0] != "cmd:exit" && got[0] != "cmd:stats" {
+		t.Errorf("Search() with history = %v, want a used command first", got)
+	}
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ParamType tells the palette how to prompt for a parameter and the registry
+// how to validate it
+type ParamType string
+
+const (
+	ParamString   ParamType = "string"   // Free text
+	ParamDate     ParamType = "date"     // YYYY-MM-DD; "today" and "yesterday" are accepted
+	ParamEnum     ParamType = "enum"     // One of Param.Options
+	ParamFile     ParamType = "file"     // Path to an existing file
+	ParamPath     ParamType = "path"     // Path to an existing file or folder
+	ParamNotebook ParamType = "notebook" // Name of a configured notebook
+	ParamNumber   ParamType = "number"   // Positive whole number
+)
+
+// Param describes one positional argument of a command
+type Param struct {
+	Name     string    `json:"name" yaml:"name"`
+	Type     ParamType `json:"type" yaml:"type"`
+	Prompt   string    `json:"prompt" yaml:"prompt"`     // Placeholder shown while asking for the value
+	Required bool      `json:"required" yaml:"required"` // The palette only prompts for required parameters
+	Default  string    `json:"default" yaml:"default"`   // Used when the argument is omitted
+	Options  []string  `json:"options" yaml:"options"`   // Allowed values for ParamEnum
+}
+
+// Completion is a suggested value for a parameter
+type Completion struct {
+	Value       string `json:"value"`       // Passed to the command when chosen
+	Label       string `json:"label"`       // Shown in the palette
+	Description string `json:"description"` // Secondary text
+}
+
+// Provider returns completions for what the user has typed so far
+type Provider func(prefix string) []Completion
+
+// Args holds a command's arguments by parameter name, after defaults were
+// applied and values validated
+type Args map[string]string
+
+// Get returns the named argument ("" when absent)
+func (a Args) Get(name string) string {
+	return a[name]
+}
+
+// normalize validates value for p and returns it in canonical form
+func (p Param) normalize(value string, known Provider) (string, error) {
+	switch p.Type {
+	case ParamDate:
+		now := time.Now()
+		switch value {
+		case "today":
+			return now.Format("2006-01-02"), nil
+		case "yesterday":
+			return now.AddDate(0, 0, -1).Format("2006-01-02"), nil
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("%s: invalid date %q, use YYYY-MM-DD", p.Name, value)
+		}
+	case ParamEnum:
+		if !slices.Contains(p.Options, value) {
+			return "", fmt.Errorf("%s: must be one of %v", p.Name, p.Options)
+		}
+	case ParamFile:
+		info, err := os.Stat(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", p.Name, err)
+		}
+		if info.IsDir() {
+			return "", fmt.Errorf("%s: %s is a directory", p.Name, value)
+		}
+	case ParamPath:
+		if _, err := os.Stat(value); err != nil {
+			return "", fmt.Errorf("%s: %w", p.Name, err)
+		}
+	case ParamNumber:
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return "", fmt.Errorf("%s: %q is not a positive number", p.Name, value)
+		}
+	case ParamNotebook:
+		// Notebooks live in the config, so the registered completer is the list of valid names
+		if known != nil && !slices.ContainsFunc(known(""), func(c Completion) bool { return c.Value == value }) {
+			return "", fmt.Errorf("%s: unknown notebook %q", p.Name, value)
+		}
+	}
+	return value, nil
+}
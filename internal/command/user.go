@@ -0,0 +1,177 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserCommandFiles are the file names (in the config directory) user commands
+// are read from, in order of preference
+var UserCommandFiles = []string{"commands.json", "commands.yaml", "commands.yml"}
+
+// UserIDPrefix keeps user command IDs apart from the built-in cmd: ones
+const UserIDPrefix = "user:"
+
+// ActionType says what an Action does
+type ActionType string
+
+const (
+	ActionInsert  ActionType = "insert"   // Insert Text at the cursor in the capture window
+	ActionAppend  ActionType = "append"   // Save Text as a new note
+	ActionOpenURL ActionType = "open-url" // Open URL in the browser
+	ActionOpen    ActionType = "open"     // Open Path with the system default application
+	ActionShell   ActionType = "shell"    // Run Command with Args (not through a shell)
+)
+
+// Action is one step of a user command. Text, URL, Path, Command and Args may
+// contain variables such as {date}, {file} or {selection} (see Expand).
+type Action struct {
+	Type    ActionType `json:"type" yaml:"type"`
+	Text    string     `json:"text,omitempty" yaml:"text,omitempty"`
+	URL     string     `json:"url,omitempty" yaml:"url,omitempty"`
+	Path    string     `json:"path,omitempty" yaml:"path,omitempty"`
+	Command string     `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string   `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// UserCommand is a palette command defined in commands.json or commands.yaml
+type UserCommand struct {
+	ID          string   `json:"id" yaml:"id"`
+	Title       string   `json:"title" yaml:"title"`
+	Description string   `json:"description" yaml:"description"`
+	Aliases     []string `json:"aliases" yaml:"aliases"`
+	Params      []Param  `json:"params" yaml:"params"`
+	Actions     []Action `json:"actions" yaml:"actions"`
+}
+
+// Command returns the palette entry for the user command
+func (u UserCommand) Command() Command {
+	return Command{
+		ID:          u.ID,
+		Title:       u.Title,
+		Description: u.Description,
+		Usage:       strings.TrimPrefix(u.ID, UserIDPrefix),
+		Aliases:     u.Aliases,
+		Params:      u.Params,
+	}
+}
+
+// LoadUserCommands reads the first of UserCommandFiles found in dir. It returns
+// the file it read ("" when there is none) so callers can report errors against it.
+// IDs are prefixed with UserIDPrefix.
+func LoadUserCommands(dir string) ([]UserCommand, string, error) {
+	for _, name := range UserCommandFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, path, err
+		}
+
+		var file struct {
+			Commands []UserCommand `json:"commands" yaml:"commands"`
+		}
+		if filepath.Ext(name) == ".json" {
+			err = json.Unmarshal(data, &file)
+		} else {
+			err = yaml.Unmarshal(data, &file)
+		}
+		if err != nil {
+			return nil, path, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		cmds, err := validateUserCommands(file.Commands)
+		if err != nil {
+			return nil, path, fmt.Errorf("%s: %w", path, err)
+		}
+		return cmds, path, nil
+	}
+	return nil, "", nil
+}
+
+func validateUserCommands(cmds []UserCommand) ([]UserCommand, error) {
+	seen := map[string]bool{}
+	for i := range cmds {
+		c := &cmds[i]
+		if c.ID == "" {
+			return nil, fmt.Errorf("command %d: id is required", i+1)
+		}
+		c.ID = UserIDPrefix + strings.TrimPrefix(c.ID, UserIDPrefix)
+		if seen[c.ID] {
+			return nil, fmt.Errorf("duplicate command id %s", c.ID)
+		}
+		seen[c.ID] = true
+
+		if c.Title == "" {
+			c.Title = strings.TrimPrefix(c.ID, UserIDPrefix)
+		}
+		if len(c.Actions) == 0 {
+			return nil, fmt.Errorf("%s: at least one action is required", c.ID)
+		}
+
+		// Required parameters must come first: the palette only prompts for those
+		// and passes them positionally
+		optional := false
+		for j, p := range c.Params {
+			if p.Name == "" {
+				return nil, fmt.Errorf("%s: parameter %d needs a name", c.ID, j+1)
+			}
+			if p.Type == "" {
+				c.Params[j].Type = ParamString
+			}
+			if p.Required && optional {
+				return nil, fmt.Errorf("%s: required parameter %s follows an optional one", c.ID, p.Name)
+			}
+			optional = optional || !p.Required
+		}
+
+		for j, a := range c.Actions {
+			var missing string
+			switch a.Type {
+			case ActionInsert, ActionAppend:
+				if a.Text == "" {
+					missing = "text"
+				}
+			case ActionOpenURL:
+				if a.URL == "" {
+					missing = "url"
+				}
+			case ActionOpen:
+				if a.Path == "" {
+					missing = "path"
+				}
+			case ActionShell:
+				if a.Command == "" {
+					missing = "command"
+				}
+			default:
+				return nil, fmt.Errorf("%s: action %d has unknown type %q", c.ID, j+1, a.Type)
+			}
+			if missing != "" {
+				return nil, fmt.Errorf("%s: %s action needs %s", c.ID, a.Type, missing)
+			}
+		}
+	}
+	return cmds, nil
+}
+
+var variableRegex = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces {name} with vars[name]. Unknown variables are left as written
+// so a typo shows up in the output instead of silently vanishing.
+func Expand(s string, vars map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[m[1:len(m)-1]]; ok {
+			return v
+		}
+		return m
+	})
+}
@@ -1,17 +1,74 @@
 package config
 
+import "encoding/json"
+
 // AppConfig represents the application configuration
 type AppConfig struct {
-	RootPath    string `json:"root_path"`    // Root directory for notes
-	Hotkey      string `json:"hotkey"`       // Global hotkey to toggle window
-	HistoryDays int    `json:"history_days"` // Number of days to show in history
+	Version             int         `json:"version"`               // Schema version, see CurrentVersion
+	RootPath            string      `json:"root_path"`             // Root directory for notes (default notebook)
+	Layout              string      `json:"layout"`                // Folder layout of the default notebook, see Notebook.Layout
+	Hotkey              string      `json:"hotkey"`                // Global hotkey to toggle window
+	Notebooks           []Notebook  `json:"notebooks"`             // Additional named notebooks
+	ActiveNotebook      string      `json:"active_notebook"`       // Notebook used by capture and history ("" = default)
+	HistoryDays         int         `json:"history_days"`          // Number of days to show in history
+	Image               ImageConfig `json:"image"`                 // Processing applied to pasted images
+	AttachmentTrashDays int         `json:"attachment_trash_days"` // Days to keep orphaned attachments in trash (0 = forever)
+	AttachmentLinks     string      `json:"attachment_links"`      // LinksAbsolute or LinksRelative
+	UndoMinutes         int         `json:"undo_minutes"`          // How long a saved note can be undone (0 = never)
+
+	extra map[string]json.RawMessage // Unknown keys, preserved on save (see json.go)
+}
+
+// Notebook folder layouts (mirrors note.Layout)
+const (
+	LayoutMonthly = "monthly" // RootPath/YYYY/MM/YYYY-MM-DD.md
+	LayoutYearly  = "yearly"  // RootPath/YYYY/YYYY-MM-DD.md
+	LayoutFlat    = "flat"    // RootPath/YYYY-MM-DD.md
+)
+
+// Attachment link styles
+const (
+	LinksAbsolute = "absolute" // /attachments/YYYY/MM/Attachment/x.png, served by the app
+	LinksRelative = "relative" // Attachment/x.png relative to the daily file, portable markdown
+)
+
+// ImageConfig controls how image attachments are processed before being saved
+type ImageConfig struct {
+	Enabled       bool   `json:"enabled"`        // Process images at all; when false they are stored as-is
+	MaxWidth      int    `json:"max_width"`      // Downscale images wider than this (0 = no limit)
+	MaxHeight     int    `json:"max_height"`     // Downscale images taller than this (0 = no limit)
+	Format        string `json:"format"`         // "original", "jpeg" or "png"
+	Quality       int    `json:"quality"`        // JPEG quality (1-100)
+	StripMetadata bool   `json:"strip_metadata"` // Remove EXIF/GPS and text metadata
+	ThumbnailSize int    `json:"thumbnail_size"` // Longest edge of generated thumbnails (0 = disabled)
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *AppConfig {
 	return &AppConfig{
-		RootPath:    "QuickNotes", // Will be relative to user home if not absolute
-		Hotkey:      "Ctrl+Alt+Space",
-		HistoryDays: 3,
+		Version:             CurrentVersion,
+		RootPath:            ResolveRoot(DefaultRootName),
+		Layout:              LayoutMonthly,
+		Hotkey:              "Ctrl+Alt+Space",
+		Notebooks:           []Notebook{},
+		ActiveNotebook:      DefaultNotebook,
+		HistoryDays:         3,
+		Image:               DefaultImageConfig(),
+		AttachmentTrashDays: 30,
+		AttachmentLinks:     LinksAbsolute,
+		UndoMinutes:         10,
+	}
+}
+
+// DefaultImageConfig returns the default image processing settings
+func DefaultImageConfig() ImageConfig {
+	return ImageConfig{
+		Enabled:       true,
+		MaxWidth:      1920,
+		MaxHeight:     1920,
+		Format:        "original",
+		Quality:       85,
+		StripMetadata: true,
+		ThumbnailSize: 240,
 	}
 }
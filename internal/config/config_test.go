@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// compact strips the whitespace of a JSON value
+func compact(t *testing.T, data json.RawMessage) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		t.Fatalf("invalid JSON %q: %v", data, err)
+	}
+	return buf.String()
+}
+
+func TestMigrateV1RootPath(t *testing.T) {
+	dir := t.TempDir()
+	abs := filepath.Join(dir, "elsewhere")
+
+	tests := []struct {
+		name string
+		root string
+		want string
+	}{
+		{"relative is anchored to the file's folder", "notes", filepath.Join(dir, "notes")},
+		{"dot segments are cleaned", "./a/../notes", filepath.Join(dir, "notes")},
+		{"absolute is kept", abs, abs},
+		{"empty is kept", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, _ := json.Marshal(tt.root)
+			raw := map[string]json.RawMessage{"root_path": root}
+
+			migrated, err := migrate(raw, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !migrated {
+				t.Errorf("version 1 document not reported as migrated")
+			}
+			var got string
+			if err := json.Unmarshal(raw["root_path"], &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("root_path = %q, want %q", got, tt.want)
+			}
+			if string(raw["version"]) != "2" {
+				t.Errorf("version = %s, want 2", raw["version"])
+			}
+		})
+	}
+}
+
+func TestMigrateVersions(t *testing.T) {
+	raw := map[string]json.RawMessage{"version": json.RawMessage("2"), "root_path": json.RawMessage(`"notes"`)}
+	migrated, err := migrate(raw, t.TempDir())
+	if err != nil || migrated {
+		t.Errorf("current version: migrated=%v err=%v", migrated, err)
+	}
+	if string(raw["root_path"]) != `"notes"` {
+		t.Errorf("current version was rewritten: %s", raw["root_path"])
+	}
+
+	if _, err := migrate(map[string]json.RawMessage{"version": json.RawMessage("99")}, ""); err == nil {
+		t.Errorf("newer version: expected an error")
+	}
+	if _, err := migrate(map[string]json.RawMessage{"version": json.RawMessage(`"two"`)}, ""); err == nil {
+		t.Errorf("invalid version: expected an error")
+	}
+}
+
+func TestMigrateLegacyConfigAnchorsToItsFolder(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	wd := t.TempDir()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(wd); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(old)
+
+	legacy := `{"root_path": "MyNotes", "hotkey": "Ctrl+Alt+N", "future_setting": {"a": 1}}`
+	if err := os.WriteFile(filepath.Join(wd, ConfigFileName), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "t-log", ConfigFileName)
+	cfg, source, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != path {
+		t.Errorf("source = %q, want %q", source, path)
+	}
+	// Where version 1 actually kept the notes, not under the home directory
+	if want := filepath.Join(wd, "MyNotes"); cfg.RootPath != want {
+		t.Errorf("root_path = %q, want %q", cfg.RootPath, want)
+	}
+	if cfg.Hotkey != "Ctrl+Alt+N" {
+		t.Errorf("hotkey = %q", cfg.Hotkey)
+	}
+	if compact(t, cfg.extra["future_setting"]) != `{"a":1}` {
+		t.Errorf("unknown key lost: %v", cfg.extra)
+	}
+
+	// Loading again must give the same folder even from another directory
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.RootPath != cfg.RootPath {
+		t.Errorf("second load root_path = %q, want %q", again.RootPath, cfg.RootPath)
+	}
+}
+
+func TestRelativeRootsResolveAgainstHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if got, want := DefaultConfig().RootPath, filepath.Join(home, DefaultRootName); got != want {
+		t.Errorf("default root = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	doc := `{"version": 2, "root_path": "Log", "layout": "monthly", "hotkey": "Ctrl+Alt+Space",
+		"notebooks": [{"name": "work", "root_path": "Work/Log", "layout": "flat"}]}`
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "Log"); cfg.RootPath != want {
+		t.Errorf("root_path = %q, want %q", cfg.RootPath, want)
+	}
+	if want := filepath.Join(home, "Work", "Log"); cfg.Notebooks[0].RootPath != want {
+		t.Errorf("notebook root_path = %q, want %q", cfg.Notebooks[0].RootPath, want)
+	}
+}
+
+func TestLoadCreatesDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	// No legacy file in the working directory
+	old, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(old)
+
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	cfg, _, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, DefaultRootName); cfg.RootPath != want {
+		t.Errorf("root_path = %q, want %q", cfg.RootPath, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("default config not written: %v", err)
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *AppConfig)
+		fields []string
+	}{
+		{"defaults are valid", func(c *AppConfig) {}, nil},
+		{"empty root", func(c *AppConfig) { c.RootPath = "  " }, []string{"root_path"}},
+		{"bad layout", func(c *AppConfig) { c.Layout = "weekly" }, []string{"layout"}},
+		{"history days", func(c *AppConfig) { c.HistoryDays = 0 }, []string{"history_days"}},
+		{"unknown active notebook", func(c *AppConfig) { c.ActiveNotebook = "nope" }, []string{"active_notebook"}},
+		{"duplicate notebook", func(c *AppConfig) {
+			c.Notebooks = []Notebook{
+				{Name: "work", RootPath: "/w", Layout: LayoutMonthly},
+				{Name: "work", RootPath: "/x", Layout: LayoutMonthly},
+			}
+		}, []string{"notebooks.1.name"}},
+		{"notebook named like the default", func(c *AppConfig) {
+			c.Notebooks = []Notebook{{Name: DefaultNotebook, RootPath: "/w", Layout: LayoutMonthly}}
+		}, []string{"notebooks.0.name"}},
+		{"notebook without root", func(c *AppConfig) {
+			c.Notebooks = []Notebook{{Name: "work", Layout: LayoutMonthly}}
+		}, []string{"notebooks.0.root_path"}},
+		{"notebook hotkey clash", func(c *AppConfig) {
+			c.Notebooks = []Notebook{{Name: "work", RootPath: "/w", Layout: LayoutMonthly, Hotkey: c.Hotkey}}
+		}, []string{"notebooks.0.hotkey"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			var got []string
+			for _, fe := range cfg.FieldErrors() {
+				got = append(got, fe.Field)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("fields = %v, want %v", got, tt.fields)
+			}
+			if (cfg.Validate() == nil) != (len(tt.fields) == 0) {
+				t.Errorf("Validate() = %v", cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Layout = "weekly"
+	cfg.HistoryDays = -1
+	cfg.Notebooks = []Notebook{
+		{Name: "good", RootPath: "/good", Layout: LayoutFlat},
+		{Name: "bad name!", RootPath: "/bad", Layout: LayoutFlat},
+	}
+
+	if fixed := cfg.Repair(); len(fixed) != 3 {
+		t.Errorf("fixed %d problems, want 3: %v", len(fixed), fixed)
+	}
+	def := DefaultConfig()
+	if cfg.Layout != def.Layout || cfg.HistoryDays != def.HistoryDays {
+		t.Errorf("fields not reset: layout=%q history_days=%d", cfg.Layout, cfg.HistoryDays)
+	}
+	if len(cfg.Notebooks) != 1 || cfg.Notebooks[0].Name != "good" {
+		t.Errorf("notebooks = %+v, want only the good one", cfg.Notebooks)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("repaired config is invalid: %v", err)
+	}
+}
+
+func TestRoundTripKeepsUnknownKeys(t *testing.T) {
+	doc := `{"version": 2, "root_path": "/notes", "layout": "yearly", "hotkey": "Ctrl+Alt+Space",
+		"zeta": [1, 2], "alpha": {"nested": true}}`
+
+	cfg := DefaultConfig()
+	if err := json.Unmarshal([]byte(doc), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RootPath != "/notes" || cfg.Layout != "yearly" {
+		t.Errorf("known fields not decoded: %+v", cfg)
+	}
+	if len(cfg.extra) != 2 {
+		t.Fatalf("extra = %v, want alpha and zeta", cfg.extra)
+	}
+
+	data, err := json.Marshal(*cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("marshalled config is not valid JSON: %v\n%s", err, data)
+	}
+	if compact(t, out["alpha"]) != `{"nested":true}` || compact(t, out["zeta"]) != `[1,2]` {
+		t.Errorf("unknown keys changed: alpha=%s zeta=%s", out["alpha"], out["zeta"])
+	}
+	if strings.Index(string(data), `"alpha"`) > strings.Index(string(data), `"zeta"`) {
+		t.Errorf("unknown keys not sorted: %s", data)
+	}
+
+	// Through the files, as a save from the settings window does
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact(t, loaded.extra["zeta"]) != `[1,2]` {
+		t.Errorf("unknown key lost on save and load: %v", loaded.extra)
+	}
+}
+
+func TestLoadFallsBackToBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	cfg := DefaultConfig()
+	cfg.RootPath = filepath.Join(t.TempDir(), "first")
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.RootPath = filepath.Join(t.TempDir(), "second")
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"root_path": "/trunc`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, source, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != BackupPath(path) {
+		t.Errorf("source = %q, want the backup", source)
+	}
+	if !strings.HasSuffix(loaded.RootPath, "first") {
+		t.Errorf("root_path = %q, want the backed up one", loaded.RootPath)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// knownKeys are the top-level JSON keys AppConfig understands
+var knownKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(AppConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// appConfigFields has AppConfig's fields without its JSON methods,
+// so they can use the default encoding
+type appConfigFields AppConfig
+
+// UnmarshalJSON decodes the known fields and keeps any other top-level keys
+// so that settings written by newer versions or by hand survive a save.
+func (c *AppConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*appConfigFields)(c)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c.extra = nil
+	for key, value := range raw {
+		if knownKeys[key] {
+			continue
+		}
+		if c.extra == nil {
+			c.extra = make(map[string]json.RawMessage)
+		}
+		c.extra[key] = value
+	}
+	return nil
+}
+
+// MarshalJSON encodes the known fields in declaration order followed by any
+// preserved unknown keys (sorted, for stable output).
+func (c AppConfig) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(appConfigFields(c))
+	if err != nil || len(c.extra) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(c.extra))
+	for key := range c.extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1]) // drop the closing brace
+	for _, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(c.extra[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
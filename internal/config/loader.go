@@ -2,50 +2,119 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"t-log/internal/fsutil"
 )
 
 const ConfigFileName = "config.json"
 
-// LoadConfig loads the configuration from config.json or creates a default one
-func LoadConfig() (*AppConfig, error) {
-	configPath := ConfigFileName // Currently relative to CWD, can be improved to AppData
+// BackupPath returns where the last good copy of the config at path is kept
+func BackupPath(path string) string {
+	return path + ".bak"
+}
+
+// LoadConfig loads the configuration from configPath (see ResolveConfigPath).
+// A config.json from an older version is migrated on first run; otherwise a
+// default one is created.
+//
+// If the file is corrupt (e.g. truncated by a crash) the backup written by
+// SaveConfig is used instead. The second return value is the file the config
+// was actually read from, so callers can tell the user.
+func LoadConfig(configPath string) (*AppConfig, string, error) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if _, err := migrateLegacyConfig(configPath); err != nil {
+			fmt.Printf("Error migrating legacy config: %v\n", err)
+		}
+	}
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// Create default config
 		defaultCfg := DefaultConfig()
+		if err := SaveConfig(configPath, defaultCfg); err != nil {
+			return nil, "", err
+		}
+		return defaultCfg, configPath, nil
+	}
 
-		// Try to resolve default root path to user home
-		home, err := os.UserHomeDir()
-		if err == nil {
-			defaultCfg.RootPath = filepath.Join(home, "QuickNotes")
+	cfg, migrated, err := readConfig(configPath)
+	if err != nil {
+		backupPath := BackupPath(configPath)
+		backup, _, backupErr := readConfig(backupPath)
+		if backupErr != nil {
+			return nil, "", fmt.Errorf("failed to load config %s: %w", configPath, err)
 		}
+		// Leave the broken file alone: the user may want to inspect it, and the
+		// next save replaces it anyway
+		fmt.Printf("Config %s is unreadable (%v), using backup %s\n", configPath, err, backupPath)
+		return backup, backupPath, nil
+	}
 
-		if err := SaveConfig(configPath, defaultCfg); err != nil {
-			return nil, err
+	if migrated {
+		if err := SaveConfig(configPath, cfg); err != nil {
+			fmt.Printf("Error saving migrated config: %v\n", err)
 		}
-		return defaultCfg, nil
 	}
 
-	file, err := os.ReadFile(configPath)
+	return cfg, configPath, nil
+}
+
+// readConfig parses one config file, upgrading older schema versions and
+// repairing invalid values. It reports whether a migration was applied.
+func readConfig(path string) (*AppConfig, bool, error) {
+	file, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	var cfg AppConfig
-	if err := json.Unmarshal(file, &cfg); err != nil {
-		return nil, err
+	// Upgrade older schema versions before decoding into the current struct
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(file, &raw); err != nil {
+		return nil, false, err
 	}
+	migrated, err := migrate(raw, filepath.Dir(path))
+	if err != nil {
+		return nil, false, err
+	}
+	if file, err = json.Marshal(raw); err != nil {
+		return nil, false, err
+	}
+
+	// Start from defaults so settings missing from older files keep sane values
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(file, cfg); err != nil {
+		return nil, false, err
+	}
+
+	// One bad value should not throw away the rest of the user's settings
+	for _, fe := range cfg.Repair() {
+		fmt.Printf("Invalid config value %s (%s), using default\n", fe.Field, fe.Message)
+	}
+	cfg.resolveRoots()
 
-	return &cfg, nil
+	return cfg, migrated, nil
 }
 
-// SaveConfig saves the configuration to disk
+// SaveConfig saves the configuration to disk. The write is atomic, and the
+// previous file is kept as a backup if it was valid.
 func SaveConfig(path string, cfg *AppConfig) error {
+	cfg.Version = CurrentVersion
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Never replace a good backup with a corrupt file
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) {
+		if err := fsutil.WriteFileAtomic(BackupPath(path), current, 0644); err != nil {
+			fmt.Printf("Error backing up config: %v\n", err)
+		}
+	}
+
+	return fsutil.WriteFileAtomic(path, data, 0644)
 }
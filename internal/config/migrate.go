@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// CurrentVersion is the config schema version written by this build.
+// Bump it and add an entry to migrations whenever the on-disk format changes.
+const CurrentVersion = 2
+
+// migration upgrades a raw config document from one version to the next.
+// dir is the folder of the file being upgraded.
+type migration func(raw map[string]json.RawMessage, dir string) error
+
+// migrations[n] upgrades version n to n+1. Files written before versioning
+// existed have no "version" key and are treated as version 1.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+}
+
+// migrate runs every migration needed to bring raw, read from a file in dir,
+// up to CurrentVersion. It reports whether anything changed so the caller can
+// persist the result.
+func migrate(raw map[string]json.RawMessage, dir string) (bool, error) {
+	version, err := rawVersion(raw)
+	if err != nil {
+		return false, err
+	}
+	if version > CurrentVersion {
+		return false, fmt.Errorf("config version %d is newer than supported version %d", version, CurrentVersion)
+	}
+
+	migrated := false
+	for ; version < CurrentVersion; version++ {
+		step, ok := migrations[version]
+		if !ok {
+			return false, fmt.Errorf("no migration from config version %d", version)
+		}
+		if err := step(raw, dir); err != nil {
+			return false, fmt.Errorf("migrating config from version %d: %w", version, err)
+		}
+		migrated = true
+	}
+
+	raw["version"] = json.RawMessage(fmt.Sprint(CurrentVersion))
+	return migrated, nil
+}
+
+// rawVersion returns the schema version of a raw config document
+func rawVersion(raw map[string]json.RawMessage) (int, error) {
+	version := 1
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return 0, fmt.Errorf("invalid config version: %w", err)
+		}
+	}
+	return version, nil
+}
+
+// migrateV1ToV2 makes a relative root_path absolute. Version 1 resolved it
+// against the working directory, which was also where config.json lived, so
+// it is anchored to the folder of the file. (A legacy file copied to the
+// config dir was already anchored to its old folder by migrateLegacyConfig.)
+func migrateV1ToV2(raw map[string]json.RawMessage, dir string) error {
+	data, ok := raw["root_path"]
+	if !ok {
+		return nil
+	}
+
+	var rootPath string
+	if err := json.Unmarshal(data, &rootPath); err != nil {
+		return fmt.Errorf("root_path: %w", err)
+	}
+	if rootPath == "" || filepath.IsAbs(rootPath) {
+		return nil
+	}
+
+	abs, err := filepath.Abs(filepath.Join(dir, rootPath))
+	if err != nil {
+		return err
+	}
+	updated, err := json.Marshal(abs)
+	if err != nil {
+		return err
+	}
+	raw["root_path"] = updated
+	return nil
+}
+
+// anchorLegacyRoot makes the root_path of a version 1 document absolute
+// against dir and returns the updated document. Other versions and documents
+// that don't parse are returned unchanged.
+func anchorLegacyRoot(data []byte, dir string) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return data, nil // readConfig reports it, and falls back to the backup
+	}
+	if version, err := rawVersion(raw); err != nil || version != 1 {
+		return data, nil
+	}
+	before := string(raw["root_path"])
+	if err := migrateV1ToV2(raw, dir); err != nil {
+		return nil, err
+	}
+	if string(raw["root_path"]) == before {
+		return data, nil
+	}
+	return json.MarshalIndent(raw, "", "  ")
+}
@@ -0,0 +1,48 @@
+package config
+
+// DefaultNotebook is the name of the notebook formed by RootPath, Layout and
+// Hotkey, which every config has
+const DefaultNotebook = "default"
+
+// Notebook is a separate log (e.g. work and personal) with its own folder
+type Notebook struct {
+	Name     string `json:"name"`      // Used in the palette and as the @name capture prefix
+	RootPath string `json:"root_path"` // Root directory for this notebook's notes
+	Layout   string `json:"layout"`    // "monthly" (default), "yearly" or "flat"
+	Hotkey   string `json:"hotkey"`    // Optional global hotkey capturing straight into this notebook
+}
+
+// AllNotebooks returns the default notebook followed by the configured ones
+func (c *AppConfig) AllNotebooks() []Notebook {
+	notebooks := make([]Notebook, 0, len(c.Notebooks)+1)
+	notebooks = append(notebooks, Notebook{
+		Name:     DefaultNotebook,
+		RootPath: c.RootPath,
+		Layout:   c.Layout,
+		Hotkey:   c.Hotkey,
+	})
+	return append(notebooks, c.Notebooks...)
+}
+
+// FindNotebook looks a notebook up by name ("" means the default notebook)
+func (c *AppConfig) FindNotebook(name string) (Notebook, bool) {
+	if name == "" {
+		name = DefaultNotebook
+	}
+	for _, nb := range c.AllNotebooks() {
+		if nb.Name == name {
+			return nb, true
+		}
+	}
+	return Notebook{}, false
+}
+
+// Active returns the notebook that capture, history and search use.
+// An unknown ActiveNotebook falls back to the default notebook.
+func (c *AppConfig) Active() Notebook {
+	if nb, ok := c.FindNotebook(c.ActiveNotebook); ok {
+		return nb
+	}
+	nb, _ := c.FindNotebook(DefaultNotebook)
+	return nb
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"t-log/internal/fsutil"
+)
+
+const (
+	// AppDirName is the folder created under the OS config directory
+	AppDirName = "t-log"
+	// EnvConfigPath overrides the config file location when set
+	EnvConfigPath = "TLOG_CONFIG"
+)
+
+// ConfigDir returns the per-user directory holding t-log's config and state:
+// %AppData%\t-log on Windows, ~/Library/Application Support/t-log on macOS
+// and $XDG_CONFIG_HOME/t-log (usually ~/.config/t-log) on Linux.
+func ConfigDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(base, AppDirName), nil
+}
+
+// ResolveConfigPath decides which config file to use, in order of precedence:
+// the override (--config flag), the TLOG_CONFIG environment variable, and
+// finally config.json inside ConfigDir.
+func ResolveConfigPath(override string) (string, error) {
+	if override != "" {
+		return filepath.Abs(override)
+	}
+	if env := os.Getenv(EnvConfigPath); env != "" {
+		return filepath.Abs(env)
+	}
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ConfigFileName), nil
+}
+
+// DefaultRootName is the notes folder, under the home directory, of a new config
+const DefaultRootName = "QuickNotes"
+
+// ResolveRoot makes a notebook root absolute. A relative root is relative to
+// the home directory, where DefaultConfig puts the notes, no matter which
+// folder the app was started from.
+func ResolveRoot(root string) string {
+	if root == "" || filepath.IsAbs(root) {
+		return root
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return root
+	}
+	return filepath.Join(home, root)
+}
+
+// resolveRoots applies ResolveRoot to the root of every notebook
+func (c *AppConfig) resolveRoots() {
+	c.RootPath = ResolveRoot(c.RootPath)
+	c.Notebooks = slices.Clone(c.Notebooks) // May be shared with the config in use
+	for i := range c.Notebooks {
+		c.Notebooks[i].RootPath = ResolveRoot(c.Notebooks[i].RootPath)
+	}
+}
+
+// legacyConfigPaths lists where older versions kept config.json: the working
+// directory and, for shortcuts with a different "Start in", the executable's folder.
+func legacyConfigPaths() []string {
+	var paths []string
+	if wd, err := os.Getwd(); err == nil {
+		paths = append(paths, filepath.Join(wd, ConfigFileName))
+	}
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exe), ConfigFileName))
+	}
+	return paths
+}
+
+// migrateLegacyConfig copies a config.json left by an older version to path.
+// It only runs while path does not exist yet, so it happens at most once;
+// the legacy file itself is left untouched.
+func migrateLegacyConfig(path string) (bool, error) {
+	for _, legacy := range legacyConfigPaths() {
+		if sameFile(legacy, path) {
+			continue
+		}
+		data, err := os.ReadFile(legacy)
+		if err != nil {
+			continue
+		}
+		// Relative paths in the old file were relative to the folder it was in
+		if data, err = anchorLegacyRoot(data, filepath.Dir(legacy)); err != nil {
+			return false, fmt.Errorf("failed to migrate %s: %w", legacy, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return false, fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := fsutil.WriteFileAtomic(path, data, 0644); err != nil {
+			return false, fmt.Errorf("failed to migrate %s: %w", legacy, err)
+		}
+		fmt.Printf("Migrated legacy config from %s to %s\n", legacy, path)
+		return true, nil
+	}
+	return false, nil
+}
+
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+// Listener is called after the configuration changed. old and new must be
+// treated as read-only.
+type Listener func(old, new *AppConfig)
+
+// Service owns the current configuration. Subsystems read it through Get and
+// Subscribe to be told when it changes, either through Update (settings UI)
+// or because config.json was edited on disk.
+type Service struct {
+	path   string
+	source string // File the current config was read from (path or its backup)
+
+	mu        sync.RWMutex
+	cfg       *AppConfig
+	listeners map[int]Listener
+	nextID    int
+}
+
+// NewService loads the config at path (see LoadConfig). If neither the file
+// nor its backup can be read the defaults are used, so the app can still start.
+func NewService(path string) *Service {
+	cfg, source, err := LoadConfig(path)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		cfg = DefaultConfig()
+	}
+
+	return &Service{
+		path:      path,
+		source:    source,
+		cfg:       cfg,
+		listeners: make(map[int]Listener),
+	}
+}
+
+// Path returns the config file this service reads and writes
+func (s *Service) Path() string {
+	return s.path
+}
+
+// Source returns the file the current config was loaded from: Path, its
+// backup when Path was corrupt, or "" when the defaults are in use
+func (s *Service) Source() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.source
+}
+
+// Get returns the current configuration. The returned value is never mutated
+// by the service; updates swap in a new one.
+func (s *Service) Get() *AppConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// Update validates cfg, saves it and notifies subscribers
+func (s *Service) Update(cfg AppConfig) error {
+	cfg.resolveRoots()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := SaveConfig(s.path, &cfg); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.source = s.path
+	s.mu.Unlock()
+	s.swap(&cfg)
+	return nil
+}
+
+// Reload re-reads the config file and notifies subscribers if it changed
+func (s *Service) Reload() error {
+	cfg, source, err := LoadConfig(s.path)
+	if err != nil {
+		return err
+	}
+	// A file that fails to parse mid-edit must not roll the app back to the backup
+	if source != s.path {
+		return fmt.Errorf("%s is not valid, keeping the current config", s.path)
+	}
+	s.mu.Lock()
+	s.source = source
+	s.mu.Unlock()
+	s.swap(cfg)
+	return nil
+}
+
+// Subscribe registers l to be called on every change. The returned function
+// removes the subscription.
+func (s *Service) Subscribe(l Listener) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.listeners[id] = l
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.listeners, id)
+	}
+}
+
+// swap installs cfg and notifies listeners unless nothing actually changed
+// (e.g. the watcher seeing our own write)
+func (s *Service) swap(cfg *AppConfig) {
+	s.mu.Lock()
+	old := s.cfg
+	if sameConfig(old, cfg) {
+		s.mu.Unlock()
+		return
+	}
+	s.cfg = cfg
+	listeners := make([]Listener, 0, len(s.listeners))
+	for _, l := range s.listeners {
+		listeners = append(listeners, l)
+	}
+	s.mu.Unlock()
+
+	// Call listeners outside the lock so they may call Get
+	for _, l := range listeners {
+		l(old, cfg)
+	}
+}
+
+func sameConfig(a, b *AppConfig) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
+
+// Watch reloads the config whenever the file changes on disk, until ctx is done
+func (s *Service) Watch(ctx context.Context) error {
+	// Editors fire several events per save; wait for them to settle
+	err := fsutil.WatchFiles(ctx, filepath.Dir(s.path), []string{filepath.Base(s.path)}, 300*time.Millisecond, func() {
+		if err := s.Reload(); err != nil {
+			// Keep running on the last good config while the file is half-edited
+			fmt.Printf("Error reloading config: %v\n", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a problem with a single config field.
+// Field is the JSON path of the field, e.g. "image.quality".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by Validate and lists every invalid field
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the configuration and returns a *ValidationError listing
+// every invalid field, or nil when the config is usable.
+func (c *AppConfig) Validate() error {
+	errs := c.FieldErrors()
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+// FieldErrors returns the field-level problems found by Validate (empty when valid)
+func (c *AppConfig) FieldErrors() []FieldError {
+	errs := []FieldError{}
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(c.RootPath) == "" {
+		add("root_path", "must not be empty")
+	}
+	if !validLayout(c.Layout) {
+		add("layout", "must be %s, %s or %s", LayoutMonthly, LayoutYearly, LayoutFlat)
+	}
+	if err := checkHotkey(c.Hotkey); err != nil {
+		add("hotkey", "%v", err)
+	}
+
+	names := map[string]bool{DefaultNotebook: true}
+	hotkeys := map[string]bool{normalizeHotkey(c.Hotkey): true}
+	for i, nb := range c.Notebooks {
+		field := func(name string) string { return fmt.Sprintf("notebooks.%d.%s", i, name) }
+
+		switch {
+		case !notebookNameRegex.MatchString(nb.Name):
+			add(field("name"), "must be letters, digits, - or _")
+		case names[nb.Name]:
+			add(field("name"), "duplicate notebook name %q", nb.Name)
+		}
+		names[nb.Name] = true
+
+		if strings.TrimSpace(nb.RootPath) == "" {
+			add(field("root_path"), "must not be empty")
+		}
+		if !validLayout(nb.Layout) {
+			add(field("layout"), "must be %s, %s or %s", LayoutMonthly, LayoutYearly, LayoutFlat)
+		}
+		if nb.Hotkey != "" {
+			if err := checkHotkey(nb.Hotkey); err != nil {
+				add(field("hotkey"), "%v", err)
+			} else if hotkeys[normalizeHotkey(nb.Hotkey)] {
+				add(field("hotkey"), "already used by another notebook")
+			}
+			hotkeys[normalizeHotkey(nb.Hotkey)] = true
+		}
+	}
+	if c.ActiveNotebook != "" && !names[c.ActiveNotebook] {
+		add("active_notebook", "unknown notebook %q", c.ActiveNotebook)
+	}
+	if c.HistoryDays < 1 || c.HistoryDays > 365 {
+		add("history_days", "must be between 1 and 365")
+	}
+	if c.AttachmentTrashDays < 0 {
+		add("attachment_trash_days", "must not be negative")
+	}
+	if c.AttachmentLinks != LinksAbsolute && c.AttachmentLinks != LinksRelative {
+		add("attachment_links", "must be %q or %q", LinksAbsolute, LinksRelative)
+	}
+	if c.UndoMinutes < 0 || c.UndoMinutes > 1440 {
+		add("undo_minutes", "must be between 0 and 1440")
+	}
+
+	img := c.Image
+	if img.MaxWidth < 0 {
+		add("image.max_width", "must not be negative")
+	}
+	if img.MaxHeight < 0 {
+		add("image.max_height", "must not be negative")
+	}
+	switch strings.ToLower(img.Format) {
+	case "original", "jpeg", "jpg", "png":
+	default:
+		add("image.format", "must be original, jpeg or png")
+	}
+	if img.Quality < 1 || img.Quality > 100 {
+		add("image.quality", "must be between 1 and 100")
+	}
+	if img.ThumbnailSize < 0 {
+		add("image.thumbnail_size", "must not be negative")
+	}
+
+	return errs
+}
+
+// Repair resets every invalid field to its default value and returns the
+// problems that were fixed. Used at load time so one bad value does not
+// discard the rest of the user's settings.
+func (c *AppConfig) Repair() []FieldError {
+	errs := c.FieldErrors()
+	def := DefaultConfig()
+
+	// A notebook with any bad field is dropped as a whole; guessing a
+	// replacement root for someone's notes would be worse
+	dropped := map[int]bool{}
+
+	for _, fe := range errs {
+		if i, ok := notebookIndex(fe.Field); ok {
+			dropped[i] = true
+			continue
+		}
+		switch fe.Field {
+		case "root_path":
+			c.RootPath = def.RootPath
+		case "layout":
+			c.Layout = def.Layout
+		case "active_notebook":
+			c.ActiveNotebook = def.ActiveNotebook
+		case "hotkey":
+			c.Hotkey = def.Hotkey
+		case "history_days":
+			c.HistoryDays = def.HistoryDays
+		case "attachment_trash_days":
+			c.AttachmentTrashDays = def.AttachmentTrashDays
+		case "attachment_links":
+			c.AttachmentLinks = def.AttachmentLinks
+		case "undo_minutes":
+			c.UndoMinutes = def.UndoMinutes
+		case "image.max_width":
+			c.Image.MaxWidth = def.Image.MaxWidth
+		case "image.max_height":
+			c.Image.MaxHeight = def.Image.MaxHeight
+		case "image.format":
+			c.Image.Format = def.Image.Format
+		case "image.quality":
+			c.Image.Quality = def.Image.Quality
+		case "image.thumbnail_size":
+			c.Image.ThumbnailSize = def.Image.ThumbnailSize
+		}
+	}
+
+	if len(dropped) > 0 {
+		kept := make([]Notebook, 0, len(c.Notebooks))
+		for i, nb := range c.Notebooks {
+			if !dropped[i] {
+				kept = append(kept, nb)
+			}
+		}
+		c.Notebooks = kept
+
+		// The active notebook may have been one of those dropped
+		if _, ok := c.FindNotebook(c.ActiveNotebook); !ok {
+			c.ActiveNotebook = def.ActiveNotebook
+		}
+	}
+
+	return errs
+}
+
+var notebookNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func validLayout(layout string) bool {
+	switch layout {
+	case "", LayoutMonthly, LayoutYearly, LayoutFlat:
+		return true
+	}
+	return false
+}
+
+// notebookIndex extracts N from a "notebooks.N.field" error path
+func notebookIndex(field string) (int, bool) {
+	rest, ok := strings.CutPrefix(field, "notebooks.")
+	if !ok {
+		return 0, false
+	}
+	idx, _, _ := strings.Cut(rest, ".")
+	i, err := strconv.Atoi(idx)
+	return i, err == nil
+}
+
+// normalizeHotkey makes "ctrl + alt+W" and "Ctrl+Alt+w" compare equal
+func normalizeHotkey(s string) string {
+	parts := strings.Split(s, "+")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, "+")
+}
+
+// Hotkey grammar accepted by internal/hotkey.ParseHotkey; keep the two in sync.
+// It is duplicated here so config stays free of the platform hotkey library.
+var (
+	hotkeyModifiers = map[string]bool{
+		"ctrl": true, "control": true, "alt": true, "shift": true,
+		"win": true, "cmd": true, "command": true, "super": true,
+	}
+	hotkeyNamedKeys = map[string]bool{
+		"SPACE": true, "ENTER": true, "RETURN": true, "ESC": true, "ESCAPE": true,
+		"TAB": true, "UP": true, "DOWN": true, "LEFT": true, "RIGHT": true,
+	}
+)
+
+func checkHotkey(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+
+	parts := strings.Split(s, "+")
+	for _, mod := range parts[:len(parts)-1] {
+		if !hotkeyModifiers[strings.ToLower(strings.TrimSpace(mod))] {
+			return fmt.Errorf("unknown modifier: %s", strings.TrimSpace(mod))
+		}
+	}
+
+	key := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+	if hotkeyNamedKeys[key] {
+		return nil
+	}
+	if len(key) == 1 && ((key[0] >= 'A' && key[0] <= 'Z') || (key[0] >= '0' && key[0] <= '9')) {
+		return nil
+	}
+	return fmt.Errorf("unknown key: %s", key)
+}
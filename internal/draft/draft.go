@@ -0,0 +1,254 @@
+// Package draft keeps the text being typed in the capture window on disk so
+// it survives hiding the window, restarts and crashes
+package draft
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+const (
+	// DirName is the drafts folder inside the config directory
+	DirName = "drafts"
+
+	// maxHistory is how many discarded drafts are kept
+	maxHistory = 10
+
+	currentFile = "current.json"
+	historyFile = "history.json"
+)
+
+// Draft is an unsaved note
+type Draft struct {
+	ID          string    `json:"id"`
+	Notebook    string    `json:"notebook"`    // Notebook the capture window was opened for ("" = active)
+	Content     string    `json:"content"`     // Editor text
+	Attachments []string  `json:"attachments"` // Attachment files the text links to
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Store holds the current draft and a short history of discarded ones.
+// Saves are debounced: the latest draft is written once typing pauses.
+type Store struct {
+	dir   string
+	delay time.Duration
+
+	mu      sync.Mutex
+	current *Draft      // Latest known draft (nil = none), possibly not written yet
+	dirty   bool        // current differs from disk
+	timer   *time.Timer // Pending debounced write
+}
+
+// NewStore returns the store in dir, writing drafts delay after the last Save
+func NewStore(dir string, delay time.Duration) *Store {
+	s := &Store{dir: dir, delay: delay}
+	if d, err := readJSON[Draft](filepath.Join(dir, currentFile)); err == nil {
+		s.current = &d
+	} else if !os.IsNotExist(err) {
+		fmt.Printf("Error reading draft: %v\n", err)
+	}
+	return s
+}
+
+// Save records content as the current draft. An empty draft is discarded
+// into the history, since clearing the editor by hand loses the text.
+func (s *Store) Save(notebook, content string, attachments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if content == "" {
+		if err := s.discardLocked(true); err != nil {
+			fmt.Printf("Error discarding draft: %v\n", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	if s.current == nil {
+		s.current = &Draft{ID: newID(now), CreatedAt: now}
+	}
+	s.current.Notebook = notebook
+	s.current.Content = content
+	s.current.Attachments = attachments
+	s.current.UpdatedAt = now
+	s.dirty = true
+
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.delay, func() {
+			if err := s.Flush(); err != nil {
+				fmt.Printf("Error saving draft: %v\n", err)
+			}
+		})
+	} else {
+		s.timer.Reset(s.delay)
+	}
+}
+
+// Flush writes a pending draft now (e.g. at shutdown)
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+func (s *Store) flushLocked() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if !s.dirty || s.current == nil {
+		return nil
+	}
+	if err := writeJSON(filepath.Join(s.dir, currentFile), s.current); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Current returns the current draft
+func (s *Store) Current() (Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return Draft{}, false
+	}
+	return *s.current, true
+}
+
+// Clear drops the current draft because it was saved as a note
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.discardLocked(false)
+}
+
+// discardLocked removes the current draft, first copying it to the history
+// when keep is set
+func (s *Store) discardLocked(keep bool) error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.current == nil {
+		return nil
+	}
+
+	if keep {
+		history := s.historyLocked()
+		history = append([]Draft{*s.current}, history...)
+		if len(history) > maxHistory {
+			history = history[:maxHistory]
+		}
+		if err := writeJSON(filepath.Join(s.dir, historyFile), history); err != nil {
+			return err
+		}
+	}
+
+	s.current = nil
+	s.dirty = false
+	if err := os.Remove(filepath.Join(s.dir, currentFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove draft: %w", err)
+	}
+	return nil
+}
+
+// History returns the discarded drafts, newest first
+func (s *Store) History() []Draft {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.historyLocked()
+}
+
+func (s *Store) historyLocked() []Draft {
+	history, err := readJSON[[]Draft](filepath.Join(s.dir, historyFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Error reading draft history: %v\n", err)
+		}
+		return []Draft{}
+	}
+	return history
+}
+
+// Restore makes the history draft with id current again. The draft it
+// replaces moves to the history.
+func (s *Store) Restore(id string) (Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.historyLocked()
+	for i, d := range history {
+		if d.ID != id {
+			continue
+		}
+		history = append(history[:i], history[i+1:]...)
+		if err := writeJSON(filepath.Join(s.dir, historyFile), history); err != nil {
+			return Draft{}, err
+		}
+		if err := s.discardLocked(true); err != nil {
+			return Draft{}, err
+		}
+		d.UpdatedAt = time.Now()
+		s.current = &d
+		s.dirty = true
+		return d, s.flushLocked()
+	}
+	return Draft{}, fmt.Errorf("draft not found: %s", id)
+}
+
+// Attachments returns the attachment files of every kept draft
+func (s *Store) Attachments() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var files []string
+	if s.current != nil {
+		files = append(files, s.current.Attachments...)
+	}
+	for _, d := range s.historyLocked() {
+		files = append(files, d.Attachments...)
+	}
+	return files
+}
+
+func newID(t time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+func readJSON[T any](path string) (T, error) {
+	var v T
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return v, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create drafts directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
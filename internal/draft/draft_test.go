@@ -0,0 +1,158 @@
+package draft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"t-log/internal/attachment"
+	"t-log/internal/config"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	// Long enough that only Flush writes
+	s := NewStore(dir, time.Hour)
+
+	if _, ok := s.Current(); ok {
+		t.Fatal("new store has a draft")
+	}
+
+	s.Save("work", "first thought", nil)
+	d, ok := s.Current()
+	if !ok || d.Content != "first thought" || d.Notebook != "work" {
+		t.Fatalf("current = %+v, %v", d, ok)
+	}
+	if _, err := os.Stat(filepath.Join(dir, currentFile)); !os.IsNotExist(err) {
+		t.Errorf("draft written before the delay")
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restart picks the draft up again
+	s = NewStore(dir, time.Hour)
+	if got, ok := s.Current(); !ok || got.ID != d.ID || got.Content != "first thought" {
+		t.Fatalf("after restart: current = %+v, %v", got, ok)
+	}
+
+	// Clearing the editor keeps the text in the history
+	s.Save("work", "", nil)
+	if _, ok := s.Current(); ok {
+		t.Errorf("cleared draft is still current")
+	}
+	if _, err := os.Stat(filepath.Join(dir, currentFile)); !os.IsNotExist(err) {
+		t.Errorf("cleared draft still on disk")
+	}
+	history := s.History()
+	if len(history) != 1 || history[0].ID != d.ID {
+		t.Fatalf("history = %+v", history)
+	}
+
+	// Restoring swaps it with the draft typed since
+	s.Save("", "second thought", nil)
+	second, _ := s.Current()
+	restored, err := s.Restore(d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Content != "first thought" {
+		t.Errorf("restored = %+v", restored)
+	}
+	if got, _ := s.Current(); got.ID != d.ID {
+		t.Errorf("current = %+v, want the restored draft", got)
+	}
+	if history := s.History(); len(history) != 1 || history[0].ID != second.ID {
+		t.Errorf("history = %+v, want only the replaced draft", history)
+	}
+	if _, err := s.Restore("nope"); err == nil {
+		t.Errorf("restoring an unknown draft succeeded")
+	}
+
+	// Saving the note drops the draft without keeping it
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Current(); ok {
+		t.Errorf("draft still current after Clear")
+	}
+	if history := s.History(); len(history) != 1 {
+		t.Errorf("Clear added to the history: %+v", history)
+	}
+	if _, ok := NewStore(dir, time.Hour).Current(); ok {
+		t.Errorf("cleared draft came back after a restart")
+	}
+}
+
+func TestStoreHistoryLimit(t *testing.T) {
+	s := NewStore(t.TempDir(), time.Hour)
+	for i := 0; i < maxHistory+3; i++ {
+		s.Save("", fmt.Sprintf("draft %d", i), nil)
+		s.Save("", "", nil)
+	}
+
+	history := s.History()
+	if len(history) != maxHistory {
+		t.Fatalf("history holds %d drafts, want %d", len(history), maxHistory)
+	}
+	if want := fmt.Sprintf("draft %d", maxHistory+2); history[0].Content != want {
+		t.Errorf("newest = %q, want %q", history[0].Content, want)
+	}
+}
+
+func TestPinnedAttachments(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RootPath = t.TempDir()
+	cfg.Image.Enabled = false
+	cfg.Image.ThumbnailSize = 0
+
+	m := attachment.NewManager(cfg)
+	s := NewStore(t.TempDir(), time.Hour)
+	m.SetPinned(s.Attachments)
+
+	link, err := m.SaveAttachment([]byte("png"), "shot.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "look ![shot](" + link + ")"
+	files := m.ReferencedFiles(content)
+	if len(files) != 1 {
+		t.Fatalf("referenced files = %v", files)
+	}
+	path := files[0]
+
+	kept := func() bool {
+		t.Helper()
+		if _, err := m.CollectGarbage(false); err != nil {
+			t.Fatal(err)
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	// Pasted into the editor but not saved as a note yet
+	s.Save("", content, files)
+	if !kept() {
+		t.Fatal("GC collected the attachment of the current draft")
+	}
+
+	// Cleared by hand, the draft can still be restored with its attachment
+	d, _ := s.Current()
+	s.Save("", "", nil)
+	if !kept() {
+		t.Fatal("GC collected the attachment of a draft in the history")
+	}
+
+	// Restored and then dropped, no draft holds it any more
+	if _, err := s.Restore(d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if kept() {
+		t.Error("GC kept an attachment no draft or note uses")
+	}
+}
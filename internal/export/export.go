@@ -0,0 +1,219 @@
+// Package export renders a range of notes to Markdown, standalone HTML,
+// JSON, CSV or plain text
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"t-log/internal/fsutil"
+	"t-log/internal/note"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Text     Format = "txt"
+)
+
+// Formats lists every format, for the palette and CLI help
+var Formats = []Format{Markdown, HTML, JSON, CSV, Text}
+
+// Ext returns the file extension (with dot) for f
+func (f Format) Ext() string {
+	switch f {
+	case Markdown:
+		return ".md"
+	case HTML:
+		return ".html"
+	case JSON:
+		return ".json"
+	case CSV:
+		return ".csv"
+	default:
+		return ".txt"
+	}
+}
+
+// ParseFormat accepts a format name or a file extension ("md", ".html")
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimPrefix(s, "."))
+	switch s {
+	case "markdown", "md":
+		return Markdown, nil
+	case "html", "htm":
+		return HTML, nil
+	case "json":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	case "txt", "text":
+		return Text, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (use markdown, html, json, csv or txt)", s)
+}
+
+// GroupBy decides how entries are sectioned
+type GroupBy string
+
+const (
+	ByDay GroupBy = "day" // One section per day, oldest first
+	ByTag GroupBy = "tag" // One section per #tag; an entry with several tags appears in each
+)
+
+// Untagged is the section for entries without tags when grouping by tag
+const Untagged = "(untagged)"
+
+// Options controls an export
+type Options struct {
+	Format      Format
+	GroupBy     GroupBy
+	Title       string      // Defaults to "Notes <start> – <end>"
+	Notebook    string      // Shown in the output
+	RootPath    string      // Notebook root, for resolving images (HTML)
+	Layout      note.Layout // Notebook layout, for resolving relative images (HTML)
+	TemplateDir string      // Optional folder with <format>.tmpl overriding the built-in template
+}
+
+// Entry is one exported note
+type Entry struct {
+	Date    string   `json:"date"`
+	Time    string   `json:"time"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+// Group is a section of the export
+type Group struct {
+	Key     string  `json:"key"`   // YYYY-MM-DD or tag (without #)
+	Label   string  `json:"label"` // Heading shown for the section
+	Entries []Entry `json:"entries"`
+}
+
+// Document is what templates and encoders receive
+type Document struct {
+	Title     string    `json:"title"`
+	Notebook  string    `json:"notebook"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	GroupBy   GroupBy   `json:"groupBy"`
+	Generated time.Time `json:"generated"`
+	Count     int       `json:"count"` // Number of entries (each counted once)
+	Groups    []Group   `json:"groups"`
+}
+
+// Build groups entries (oldest first, as from note.ReadEntries) into a Document
+func Build(entries []note.NoteEntry, start, end string, opts Options) Document {
+	doc := Document{
+		Title:     opts.Title,
+		Notebook:  opts.Notebook,
+		Start:     start,
+		End:       end,
+		GroupBy:   opts.GroupBy,
+		Generated: time.Now(),
+		Count:     len(entries),
+		Groups:    []Group{},
+	}
+	if doc.GroupBy == "" {
+		doc.GroupBy = ByDay
+	}
+	if doc.Title == "" {
+		doc.Title = fmt.Sprintf("Notes %s – %s", start, end)
+		if start == end {
+			doc.Title = "Notes " + start
+		}
+	}
+
+	index := map[string]int{}
+	add := func(key, label string, e Entry) {
+		i, ok := index[key]
+		if !ok {
+			i = len(doc.Groups)
+			index[key] = i
+			doc.Groups = append(doc.Groups, Group{Key: key, Label: label})
+		}
+		doc.Groups[i].Entries = append(doc.Groups[i].Entries, e)
+	}
+
+	for _, ne := range entries {
+		e := Entry{Date: ne.Date, Time: ne.Timestamp, Content: ne.Content, Tags: note.Tags(ne.Content)}
+		if doc.GroupBy == ByTag {
+			if len(e.Tags) == 0 {
+				add(Untagged, Untagged, e)
+			}
+			for _, tag := range e.Tags {
+				add(tag, "#"+tag, e)
+			}
+			continue
+		}
+		add(e.Date, dayLabel(e.Date), e)
+	}
+
+	if doc.GroupBy == ByTag {
+		// Alphabetical, untagged last
+		sort.SliceStable(doc.Groups, func(i, j int) bool {
+			if (doc.Groups[i].Key == Untagged) != (doc.Groups[j].Key == Untagged) {
+				return doc.Groups[j].Key == Untagged
+			}
+			return doc.Groups[i].Key < doc.Groups[j].Key
+		})
+	}
+	return doc
+}
+
+// dayLabel formats a date heading such as "2025-01-06 Monday"
+func dayLabel(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("2006-01-02 Monday")
+}
+
+// Render writes doc in opts.Format
+func Render(doc Document, opts Options) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch opts.Format {
+	case JSON:
+		err = renderJSON(&buf, doc)
+	case CSV:
+		err = renderCSV(&buf, doc)
+	case HTML:
+		err = renderHTML(&buf, doc, opts)
+	case Markdown, Text:
+		err = renderText(&buf, doc, opts)
+	default:
+		err = fmt.Errorf("unknown export format %q", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ToFile exports the notes of rootPath between start and end to path
+func ToFile(path, start, end string, opts Options) (Document, error) {
+	entries, err := note.ReadEntries(opts.RootPath, opts.Layout, start, end)
+	if err != nil {
+		return Document{}, err
+	}
+	doc := Build(entries, start, end, opts)
+
+	data, err := Render(doc, opts)
+	if err != nil {
+		return Document{}, err
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0644); err != nil {
+		return Document{}, fmt.Errorf("failed to write export: %w", err)
+	}
+	return doc, nil
+}
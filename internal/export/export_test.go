@@ -0,0 +1,107 @@
+package export
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"t-log/internal/note"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// sampleEntries covers two days, multi-line notes, several tags and a note
+// without a timestamp
+func sampleEntries() []note.NoteEntry {
+	var entries []note.NoteEntry
+	entries = append(entries, note.ParseEntries("2025-06-29", "Weekend\n- [09:00] Shopping list #home\n  - milk\n  - \"eggs\", bread\n- [21:15] Read <b>two</b> chapters #books #home\n")...)
+	entries = append(entries, note.ParseEntries("2025-06-30", "- [08:45] Standup moved to 10:00 #work\n- [12:30] Lunch with **Sam**\n")...)
+	return entries
+}
+
+func TestRenderGolden(t *testing.T) {
+	for _, group := range []GroupBy{ByDay, ByTag} {
+		for _, format := range Formats {
+			name := string(group) + "-" + string(format)
+			t.Run(name, func(t *testing.T) {
+				opts := Options{Format: format, GroupBy: group, Notebook: "personal"}
+				doc := Build(sampleEntries(), "2025-06-29", "2025-06-30", opts)
+				doc.Generated = time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)
+
+				got, err := Render(doc, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				golden := filepath.Join("testdata", name+format.Ext()+".golden")
+				if *update {
+					if err := os.WriteFile(golden, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run with -update to create it)", err)
+				}
+				if string(got) != string(want) {
+					t.Errorf("output differs from %s\n got:\n%s\nwant:\n%s", golden, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestBuildGroups(t *testing.T) {
+	tests := []struct {
+		group  GroupBy
+		labels []string
+		counts []int
+	}{
+		{ByDay, []string{"2025-06-29 Sunday", "2025-06-30 Monday"}, []int{3, 2}},
+		// The note tagged #books #home is in both sections
+		{ByTag, []string{"#books", "#home", "#work", Untagged}, []int{1, 2, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.group), func(t *testing.T) {
+			doc := Build(sampleEntries(), "2025-06-29", "2025-06-30", Options{GroupBy: tt.group})
+			if doc.Count != 5 {
+				t.Errorf("Count = %d, want 5", doc.Count)
+			}
+			if len(doc.Groups) != len(tt.labels) {
+				t.Fatalf("got %d groups, want %d", len(doc.Groups), len(tt.labels))
+			}
+			for i, g := range doc.Groups {
+				if g.Label != tt.labels[i] || len(g.Entries) != tt.counts[i] {
+					t.Errorf("group %d = %s with %d entries, want %s with %d", i, g.Label, len(g.Entries), tt.labels[i], tt.counts[i])
+				}
+			}
+		})
+	}
+
+	if doc := Build(nil, "2025-06-30", "2025-06-30", Options{}); doc.Title != "Notes 2025-06-30" || doc.GroupBy != ByDay {
+		t.Errorf("defaults: title %q, group %q", doc.Title, doc.GroupBy)
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "txt.tmpl"), []byte("{{.Count}} notes{{range .Groups}} {{.Key}}{{end}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Format: Text, TemplateDir: dir}
+	got, err := Render(Build(sampleEntries(), "2025-06-29", "2025-06-30", opts), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "5 notes 2025-06-29 2025-06-30\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Formats without an edited template fall back to the built-in one
+	opts.Format = Markdown
+	if _, err := Render(Build(sampleEntries(), "2025-06-29", "2025-06-30", opts), opts); err != nil {
+		t.Errorf("built-in fallback: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"t-log/internal/attachment"
+	"t-log/internal/usertmpl"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// maxEmbeddedImage is the largest image inlined into HTML exports; larger
+// ones keep their original link
+const maxEmbeddedImage = 20 << 20
+
+// ByTag reports whether the document is grouped by tag (for templates)
+func (d Document) ByTag() bool {
+	return d.GroupBy == ByTag
+}
+
+// templateSource returns the template for f: <TemplateDir>/<format>.tmpl
+// when it exists, the built-in one otherwise
+func templateSource(f Format, dir string) (string, error) {
+	return usertmpl.Source(builtinTemplates, dir, string(f)+".tmpl")
+}
+
+// textFuncs are available to the Markdown and plain text templates
+var textFuncs = template.FuncMap{
+	"indent": usertmpl.Indent,
+	"underline": func(s, char string) string {
+		return strings.Repeat(char, len([]rune(s)))
+	},
+}
+
+func renderText(w io.Writer, doc Document, opts Options) error {
+	src, err := templateSource(opts.Format, opts.TemplateDir)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(string(opts.Format)).Funcs(textFuncs).Parse(src)
+	if err != nil {
+		return fmt.Errorf("invalid %s template: %w", opts.Format, err)
+	}
+	return tmpl.Execute(w, doc)
+}
+
+func renderHTML(w io.Writer, doc Document, opts Options) error {
+	src, err := templateSource(HTML, opts.TemplateDir)
+	if err != nil {
+		return err
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		// Same as the app's preview: single newlines break lines, inline HTML such as <u> is kept
+		goldmark.WithRendererOptions(html.WithHardWraps(), html.WithUnsafe()),
+	)
+	funcs := htmltemplate.FuncMap{
+		"markdown": func(content, date string) (htmltemplate.HTML, error) {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(content), &buf); err != nil {
+				return "", err
+			}
+			return htmltemplate.HTML(embedImages(buf.String(), date, opts)), nil
+		},
+	}
+
+	tmpl, err := htmltemplate.New("html").Funcs(funcs).Parse(src)
+	if err != nil {
+		return fmt.Errorf("invalid html template: %w", err)
+	}
+	return tmpl.Execute(w, doc)
+}
+
+// imgSrcRegex matches the src attribute of rendered <img> tags
+var imgSrcRegex = regexp.MustCompile(`(<img[^>]*?\ssrc=")([^"]+)(")`)
+
+// embedImages replaces local image links with data: URIs so the HTML file
+// stands alone. Images that can't be found keep their link.
+func embedImages(body, date string, opts Options) string {
+	return imgSrcRegex.ReplaceAllStringFunc(body, func(match string) string {
+		m := imgSrcRegex.FindStringSubmatch(match)
+		src := strings.ReplaceAll(m[2], "&amp;", "&")
+		path := localImage(src, date, opts)
+		if path == "" {
+			return match
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > maxEmbeddedImage {
+			return match
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return match
+		}
+		mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+		if mimeType == "" {
+			mimeType = http.DetectContentType(data)
+		}
+		return m[1] + "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data) + m[3]
+	})
+}
+
+// localImage resolves an image link from a note written on date to an
+// attachment of the notebook, or "" for remote and unresolvable links
+func localImage(src, date string, opts Options) string {
+	if opts.RootPath == "" {
+		return ""
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return ""
+	}
+	return attachment.ResolveLink(opts.RootPath, opts.Layout.DailyFile(opts.RootPath, day), src)
+}
+
+func renderJSON(w io.Writer, doc Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
+
+// renderCSV writes one row per entry and group, so a spreadsheet can filter
+// by the group column
+func renderCSV(w io.Writer, doc Document) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"group", "date", "time", "tags", "content"}); err != nil {
+		return err
+	}
+	for _, g := range doc.Groups {
+		for _, e := range g.Entries {
+			if err := cw.Write([]string{g.Key, e.Date, e.Time, strings.Join(e.Tags, " "), e.Content}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,91 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout is returned when another process holds a file's lock for too long
+var ErrLockTimeout = errors.New("timed out waiting for file lock")
+
+// lockTimeout is a variable so tests don't have to wait it out
+var lockTimeout = 5 * time.Second
+
+const lockRetry = 20 * time.Millisecond
+
+// LockedFile is a file held under an exclusive advisory lock. Other t-log
+// processes (the app, CLI captures) taking the same lock wait for Close;
+// programs that don't lock are not blocked.
+type LockedFile struct {
+	*os.File
+}
+
+// OpenLocked opens path with flag and perm, then waits for an exclusive lock
+// on it. Editors often save by writing a new file and renaming it over the
+// old one; if that happened before the lock was acquired, the stale handle
+// is dropped and the new file is opened and locked instead, so writes never
+// go to a file that is no longer at path.
+func OpenLocked(path string, flag int, perm os.FileMode) (*LockedFile, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := waitLock(f, deadline); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if current(f, path) {
+			return &LockedFile{f}, nil
+		}
+
+		// Replaced while we waited; unlock and retry with the new file
+		unlockFile(f)
+		f.Close()
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s: %w", path, ErrLockTimeout)
+		}
+	}
+}
+
+// Close releases the lock and closes the file
+func (f *LockedFile) Close() error {
+	unlockFile(f.File)
+	return f.File.Close()
+}
+
+// waitLock polls for the lock until deadline, so a stuck holder produces an
+// error rather than hanging a save forever
+func waitLock(f *os.File, deadline time.Time) error {
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to lock file: %w", err)
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// current reports whether the open file is still the one at path
+func current(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	now, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, now)
+}
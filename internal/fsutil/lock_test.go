@@ -0,0 +1,91 @@
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenLockedTimeout(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 200 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "2025-06-30.md")
+	held, err := OpenLocked(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if f, err := OpenLocked(path, os.O_RDWR, 0644); !errors.Is(err, ErrLockTimeout) {
+		if f != nil {
+			f.Close()
+		}
+		t.Fatalf("second open: %v, want ErrLockTimeout", err)
+	}
+	if waited := time.Since(start); waited < lockTimeout {
+		t.Errorf("gave up after %s, before the %s timeout", waited, lockTimeout)
+	}
+
+	// Released: the next open gets the lock straight away
+	held.Close()
+	f, err := OpenLocked(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("open after release: %v", err)
+	}
+	f.Close()
+}
+
+func TestOpenLockedFollowsReplacedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "2025-06-30.md")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	held, err := OpenLocked(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		f   *LockedFile
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		f, err := OpenLocked(path, os.O_RDWR, 0644)
+		done <- result{f, err}
+	}()
+
+	// Let the second open block on the lock, then save the way editors do:
+	// write a new file and rename it over the old one
+	time.Sleep(5 * lockRetry)
+	replacement := filepath.Join(dir, "2025-06-30.md.tmp")
+	if err := os.WriteFile(replacement, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+	held.Close()
+
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	defer r.f.Close()
+
+	data, err := io.ReadAll(r.f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new\n" {
+		t.Errorf("locked the replaced file, read %q", data)
+	}
+	if !current(r.f.File, path) {
+		t.Errorf("locked file is not the one at path")
+	}
+}
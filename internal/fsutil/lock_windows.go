@@ -0,0 +1,30 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows locks are mandatory for the locked bytes, so the lock covers one
+// byte far past any real content; it excludes other lockers without stopping
+// editors from reading or writing the file.
+const lockOffsetHigh = 0x7fffffff
+
+// tryLockFile takes an exclusive LockFileEx lock without blocking
+func tryLockFile(f *os.File) (bool, error) {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlockFile(f *os.File) {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+}
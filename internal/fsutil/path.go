@@ -0,0 +1,14 @@
+package fsutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Within reports whether path is parent itself or inside it. Names that
+// merely start with dots, such as "..notes", are inside. Both paths must be
+// of the same kind (absolute or relative to the same folder).
+func Within(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
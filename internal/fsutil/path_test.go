@@ -0,0 +1,28 @@
+package fsutil
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	root := filepath.Join("data", "notes")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{root, true},
+		{filepath.Join(root, "2025", "06", "2025-06-30.md"), true},
+		{filepath.Join(root, "..notes"), true},
+		{filepath.Join(root, "..", "notes", "x.md"), true},
+		{filepath.Join("data"), false},
+		{filepath.Join("data", "notes-site"), false},
+		{filepath.Join(root, "..", "other"), false},
+		{filepath.Join(root, "..", "..", "x"), false},
+	}
+	for _, tt := range tests {
+		if got := Within(root, tt.path); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", root, tt.path, got, tt.want)
+		}
+	}
+}
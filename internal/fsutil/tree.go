@@ -0,0 +1,109 @@
+package fsutil
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchTree calls onChange with the paths changed anywhere under root once
+// events pause for debounce, until ctx is done. fsnotify is not recursive, so
+// every folder is watched, including ones created later; skipDir excludes
+// folders by name (and everything below them).
+func WatchTree(ctx context.Context, root string, debounce time.Duration, skipDir func(name string) bool, onChange func(paths []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	// addTree watches dir and its subfolders, returning the files found so a
+	// folder moved in whole is reported with its contents
+	addTree := func(dir string) ([]string, error) {
+		var files []string
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// A folder removed mid-walk is not an error for the watcher
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !d.IsDir() {
+				files = append(files, path)
+				return nil
+			}
+			if path != dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		})
+		return files, err
+	}
+
+	if _, err := addTree(root); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", root, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		changed := map[string]bool{}
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if skipDir(info.Name()) {
+							continue
+						}
+						files, err := addTree(event.Name)
+						if err != nil {
+							fmt.Printf("Watcher error in %s: %v\n", event.Name, err)
+						}
+						for _, f := range files {
+							changed[f] = true
+						}
+						timer.Reset(debounce)
+						continue
+					}
+				}
+				changed[event.Name] = true
+				timer.Reset(debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("Watcher error in %s: %v\n", root, err)
+			case <-timer.C:
+				paths := make([]string, 0, len(changed))
+				for path := range changed {
+					paths = append(paths, path)
+				}
+				sort.Strings(paths)
+				changed = map[string]bool{}
+				onChange(paths)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package fsutil
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchFiles calls onChange whenever one of the named files in dir is
+// written, created, renamed or removed, until ctx is done. The directory is
+// watched rather than the files because editors (and WriteFileAtomic) often
+// save by renaming a new file over the old one, and because the files may
+// not exist yet. Bursts of events are collapsed by waiting for debounce.
+func WatchFiles(ctx context.Context, dir string, names []string, debounce time.Duration, onChange func()) error {
+	return watchDir(ctx, dir, debounce, onChange, func(event fsnotify.Event) bool {
+		// Chmod is noise for config files
+		return !event.Has(fsnotify.Chmod) && slices.Contains(names, filepath.Base(event.Name))
+	})
+}
+
+// WatchMatching is WatchFiles for the files of dir that match reports true
+// for, given their full path. Permission changes count, since they can turn
+// a file into an executable.
+func WatchMatching(ctx context.Context, dir string, debounce time.Duration, match func(path string) bool, onChange func()) error {
+	return watchDir(ctx, dir, debounce, onChange, func(event fsnotify.Event) bool {
+		return match(event.Name)
+	})
+}
+
+func watchDir(ctx context.Context, dir string, debounce time.Duration, onChange func(), watched func(fsnotify.Event) bool) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if watched(event) {
+					timer.Reset(debounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("Watcher error in %s: %v\n", dir, err)
+			case <-timer.C:
+				onChange()
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseCSV reads date, time, text rows. A header row naming the columns
+// (date, time, text/content/note) may put them in any order; without one
+// they are positional, and two columns mean "date time" and text.
+func parseCSV(src string) (parsed, error) {
+	var p parsed
+	files, err := sourceFiles(src, func(name string) bool { return strings.EqualFold(filepath.Ext(name), ".csv") })
+	if err != nil {
+		return p, err
+	}
+
+	for _, path := range files {
+		if err := parseCSVFile(path, &p); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func parseCSVFile(path string, p *parsed) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+	p.Files++
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	// Column indexes; -1 when absent
+	dateCol, timeCol, textCol := 0, 1, 2
+	first := 0
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\uFEFF")
+	}
+	if cols, ok := csvHeader(header); ok {
+		dateCol, timeCol, textCol = cols[0], cols[1], cols[2]
+		first = 1
+	} else if len(header) == 2 {
+		dateCol, timeCol, textCol = 0, -1, 1
+	}
+
+	get := func(rec []string, col int) string {
+		if col < 0 || col >= len(rec) {
+			return ""
+		}
+		return rec[col]
+	}
+
+	for i := first; i < len(records); i++ {
+		rec := records[i]
+		t, err := parseDateTime(get(rec, dateCol), get(rec, timeCol))
+		if err != nil {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s:%d: %v", path, i+1, err))
+			continue
+		}
+		p.Items = append(p.Items, item{Time: t, Content: get(rec, textCol), Dir: filepath.Dir(path)})
+	}
+	return nil
+}
+
+// csvHeader finds the date, time and text columns in a header row
+func csvHeader(rec []string) ([3]int, bool) {
+	cols := [3]int{-1, -1, -1}
+	for i, name := range rec {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "date", "datetime", "timestamp":
+			cols[0] = i
+		case "time":
+			cols[1] = i
+		case "text", "content", "note", "body", "entry":
+			cols[2] = i
+		}
+	}
+	return cols, cols[0] >= 0 && cols[2] >= 0
+}
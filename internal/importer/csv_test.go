@@ -0,0 +1,50 @@
+package importer
+
+import "testing"
+
+func TestParseCSVFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []wantItem
+		skipped int
+	}{
+		{
+			name:    "positional columns",
+			content: "2025-01-06,10:30,first\n2025-01-06,11:00,\"second, with comma\"\n",
+			want:    []wantItem{{at(6, 10, 30), "first"}, {at(6, 11, 0), "second, with comma"}},
+		},
+		{
+			name:    "header in any order",
+			content: "\uFEFFNote,Time,Date\n\"two\nlines\",9:05 pm,2025/01/06\n",
+			want:    []wantItem{{at(6, 21, 5), "two\nlines"}},
+		},
+		{
+			name:    "two columns are a timestamp and text",
+			content: "2025-01-06 10:30,first\n2025-01-07T08:00:00,second\n",
+			want:    []wantItem{{at(6, 10, 30), "first"}, {at(7, 8, 0), "second"}},
+		},
+		{
+			name:    "bad rows are skipped",
+			content: "date,text\nyesterday,nope\n2025-01-06,kept\n",
+			want:    []wantItem{{at(6, 0, 0), "kept"}},
+			skipped: 1,
+		},
+		{
+			name:    "empty file",
+			content: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p parsed
+			if err := parseCSVFile(writeSource(t, "log.csv", tt.content), &p); err != nil {
+				t.Fatal(err)
+			}
+			checkItems(t, p.Items, tt.want)
+			if len(p.Skipped) != tt.skipped {
+				t.Errorf("skipped %q, want %d rows", p.Skipped, tt.skipped)
+			}
+		})
+	}
+}
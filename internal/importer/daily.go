@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// dailyFileRegex matches Obsidian (2025-01-06.md) and Logseq (2025_01_06.md) daily notes
+	dailyFileRegex = regexp.MustCompile(`^(\d{4})[-_.](\d{2})[-_.](\d{2})\.md$`)
+	// timedLineRegex matches a line starting with a time: "- 10:30 text",
+	// "- [10:30] text", "- **10:30** text" or "10:30 text"
+	timedLineRegex = regexp.MustCompile(`^(?:[-*+]\s+)?(?:\*\*|\[)?(\d{1,2}:\d{2})(?:\*\*|\])?(?:\s+(.*))?$`)
+	// blockRegex matches a top-level Logseq block
+	blockRegex = regexp.MustCompile(`^[-*+]\s?(.*)$`)
+	// propertyRegex matches Logseq block properties (id:: ..., collapsed:: true)
+	propertyRegex = regexp.MustCompile(`^\s*[A-Za-z0-9_-]+:: `)
+	// headingOnlyRegex matches blocks holding nothing but headings, e.g. a daily note template title
+	headingOnlyRegex = regexp.MustCompile(`^(?:#+ [^\n]*\n*)+$`)
+)
+
+// parseDailyNotes reads a folder of daily notes (or a single one). Lines
+// starting with a time begin an entry; notes without times become one entry
+// for the day (Obsidian) or one per top-level block (Logseq). Untimed entries
+// take the time of the entry before them, or 00:00.
+func parseDailyNotes(src string, kind Kind) (parsed, error) {
+	var p parsed
+	files, err := sourceFiles(src, func(name string) bool { return dailyFileRegex.MatchString(name) })
+	if err != nil {
+		return p, err
+	}
+
+	for _, path := range files {
+		m := dailyFileRegex.FindStringSubmatch(filepath.Base(path))
+		if m == nil {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s: file name is not a date", path))
+			continue
+		}
+		day, err := time.ParseInLocation("2006-01-02", m[1]+"-"+m[2]+"-"+m[3], time.Local)
+		if err != nil {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return p, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		p.Files++
+
+		for _, b := range splitBlocks(string(data), kind == Logseq) {
+			t, _ := time.Parse("15:04", b.clock)
+			p.Items = append(p.Items, item{
+				Time:    time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local),
+				Content: b.text,
+				Dir:     filepath.Dir(path),
+			})
+		}
+	}
+	return p, nil
+}
+
+// block is one entry of a daily note
+type block struct {
+	clock string // HH:MM
+	text  string
+}
+
+// splitBlocks cuts a daily note into entries. With perBlock every top-level
+// list item starts an entry, otherwise only timed lines do.
+func splitBlocks(content string, perBlock bool) []block {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = stripFrontmatter(content)
+
+	var blocks []block
+	var lines []string
+	clock := "00:00"
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		lines = nil
+		if text == "" || headingOnlyRegex.MatchString(text) {
+			return
+		}
+		blocks = append(blocks, block{clock: clock, text: text})
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if propertyRegex.MatchString(line) {
+			continue
+		}
+		topLevel := line != "" && line[0] != ' ' && line[0] != '\t'
+
+		if topLevel {
+			if m := timedLineRegex.FindStringSubmatch(line); m != nil {
+				flush()
+				clock = normalizeClock(m[1])
+				lines = append(lines, m[2])
+				continue
+			}
+			if m := blockRegex.FindStringSubmatch(line); m != nil && perBlock {
+				flush()
+				lines = append(lines, m[1])
+				continue
+			}
+		}
+		// Logseq indents children with tabs; two spaces read the same in markdown
+		lines = append(lines, strings.ReplaceAll(line, "\t", "  "))
+	}
+	flush()
+	return blocks
+}
+
+// stripFrontmatter drops a leading YAML "---" block
+func stripFrontmatter(content string) string {
+	if !strings.HasPrefix(content, "---\n") {
+		return content
+	}
+	if end := strings.Index(content[4:], "\n---"); end >= 0 {
+		rest := content[4+end+4:]
+		return strings.TrimPrefix(rest, "\n")
+	}
+	return content
+}
+
+// normalizeClock pads "9:05" to "09:05"
+func normalizeClock(clock string) string {
+	if len(clock) == 4 {
+		return "0" + clock
+	}
+	return clock
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		perBlock bool
+		want     []block
+	}{
+		{
+			name:    "untimed note is one entry",
+			content: "Went for a walk.\n\nNice weather.\n",
+			want:    []block{{"00:00", "Went for a walk.\n\nNice weather."}},
+		},
+		{
+			name:    "timed lines",
+			content: "- 9:05 coffee\n- [10:30] meeting\n  - notes\n- **14:00** review\n15:45 done",
+			want: []block{
+				{"09:05", "coffee"},
+				{"10:30", "meeting\n  - notes"},
+				{"14:00", "review"},
+				{"15:45", "done"},
+			},
+		},
+		{
+			name:    "text before the first time is its own entry",
+			content: "# 2025-01-06\n\nIntro line\n- 10:30 first\n",
+			want:    []block{{"00:00", "# 2025-01-06\n\nIntro line"}, {"10:30", "first"}},
+		},
+		{
+			name:    "frontmatter and heading-only blocks are dropped",
+			content: "---\ntags: [daily]\n---\n# Monday\n- 08:00 up\n",
+			want:    []block{{"08:00", "up"}},
+		},
+		{
+			name:    "CRLF",
+			content: "- 08:00 up\r\n  still up\r\n",
+			want:    []block{{"08:00", "up\n  still up"}},
+		},
+		{
+			name:     "logseq blocks",
+			content:  "- first block\n\t- child\n\tid:: 6412\n- 11:00 timed block\n- later block\ncollapsed:: true\n",
+			perBlock: true,
+			want: []block{
+				{"00:00", "first block\n  - child"},
+				{"11:00", "timed block"},
+				{"11:00", "later block"},
+			},
+		},
+		{
+			name:    "list items without times stay in one entry",
+			content: "- one\n- two\n",
+			want:    []block{{"00:00", "- one\n- two"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBlocks(tt.content, tt.perBlock)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBlocks(%q)\n got %q\nwant %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
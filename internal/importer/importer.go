@@ -0,0 +1,376 @@
+// Package importer brings logs kept in other apps into a notebook. Every
+// importer parses its source into timestamped items, which are then written
+// through note.SaveNoteAt like captured notes, skipping entries that already
+// exist.
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"t-log/internal/attachment"
+	"t-log/internal/note"
+)
+
+// Kind is a source format
+type Kind string
+
+const (
+	Obsidian Kind = "obsidian" // Folder of YYYY-MM-DD.md daily notes
+	Logseq   Kind = "logseq"   // Journals folder of YYYY_MM_DD.md, one entry per top-level block
+	Text     Kind = "text"     // Plain text with "YYYY-MM-DD HH:MM" timestamps
+	JSON     Kind = "json"     // Day One JSON export, or an array of {date, time, text}
+	CSV      Kind = "csv"      // date, time, text columns
+)
+
+// Kinds lists every importer, for the palette
+var Kinds = []Kind{Obsidian, Logseq, Text, JSON, CSV}
+
+// ParseKind accepts a kind name; "dayone" is an alias for json
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(s)); k {
+	case Obsidian, Logseq, Text, JSON, CSV:
+		return k, nil
+	case "dayone", "day-one":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown import format %q (use obsidian, logseq, text, json or csv)", s)
+}
+
+// Options says where imported notes go
+type Options struct {
+	RootPath  string
+	Layout    note.Layout
+	LinkStyle string // Attachment link style (config.AttachmentLinks)
+	DryRun    bool   // Only report what would be imported
+}
+
+// Report summarises an import (or what a dry run would import)
+type Report struct {
+	DryRun      bool     `json:"dryRun"`
+	Kind        Kind     `json:"kind"`
+	Source      string   `json:"source"`
+	Files       int      `json:"files"`       // Source files read
+	Found       int      `json:"found"`       // Entries parsed from the source
+	Imported    int      `json:"imported"`    // Entries written (or to be written)
+	Duplicates  int      `json:"duplicates"`  // Entries already in the notebook, skipped
+	Attachments int      `json:"attachments"` // Images copied into Attachment folders
+	Missing     []string `json:"missing"`     // Image references whose file was not found
+	Skipped     []string `json:"skipped"`     // "file:line: reason" for input that could not be parsed
+	Start       string   `json:"start"`       // First and last imported day (YYYY-MM-DD)
+	End         string   `json:"end"`
+}
+
+// item is one entry parsed from a source, in local time
+type item struct {
+	Time    time.Time
+	Content string
+	Dir     string // Folder relative image links are resolved against
+}
+
+// parsed is what a source parser returns
+type parsed struct {
+	Items   []item
+	Files   int
+	Skipped []string
+	Missing []string
+}
+
+// Run imports src (a file or folder) of the given kind
+func Run(kind Kind, src string, opts Options) (*Report, error) {
+	var p parsed
+	var err error
+	switch kind {
+	case Obsidian, Logseq:
+		p, err = parseDailyNotes(src, kind)
+	case Text:
+		p, err = parseText(src)
+	case JSON:
+		p, err = parseJSON(src)
+	case CSV:
+		p, err = parseCSV(src)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		DryRun:  opts.DryRun,
+		Kind:    kind,
+		Source:  src,
+		Files:   p.Files,
+		Found:   len(p.Items),
+		Missing: p.Missing,
+		Skipped: p.Skipped,
+	}
+	if report.Missing == nil {
+		report.Missing = []string{}
+	}
+	if report.Skipped == nil {
+		report.Skipped = []string{}
+	}
+
+	// Oldest first, so each daily file is appended in order
+	sort.SliceStable(p.Items, func(i, j int) bool {
+		return p.Items[i].Time.Before(p.Items[j].Time)
+	})
+
+	images := newImageResolver(src)
+	existing := map[string]map[string]bool{} // Date -> keys of entries in the daily file
+
+	for _, it := range p.Items {
+		content, copied := images.localize(it, opts, report)
+		if content == "" {
+			continue
+		}
+
+		date := it.Time.Format("2006-01-02")
+		keys, ok := existing[date]
+		if !ok {
+			if keys, err = existingKeys(opts.RootPath, opts.Layout, it.Time); err != nil {
+				return nil, err
+			}
+			existing[date] = keys
+		}
+		key := entryKey(it.Time.Format("15:04"), content)
+		if keys[key] {
+			report.Duplicates++
+			continue
+		}
+		keys[key] = true
+
+		if !opts.DryRun {
+			if _, err := note.SaveNoteAt(opts.RootPath, opts.Layout, content, it.Time); err != nil {
+				return report, fmt.Errorf("failed to import entry of %s: %w", it.Time.Format("2006-01-02 15:04"), err)
+			}
+		}
+		report.Imported++
+		report.Attachments += copied
+		if report.Start == "" || date < report.Start {
+			report.Start = date
+		}
+		if date > report.End {
+			report.End = date
+		}
+	}
+	return report, nil
+}
+
+// entryKey identifies an entry for duplicate detection
+func entryKey(hhmm, content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return hhmm + "\x00" + strings.TrimSpace(content)
+}
+
+// existingKeys reads the entries already in the daily file for day
+func existingKeys(rootPath string, layout note.Layout, day time.Time) (map[string]bool, error) {
+	keys := map[string]bool{}
+	data, err := os.ReadFile(layout.DailyFile(rootPath, day))
+	if os.IsNotExist(err) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daily note: %w", err)
+	}
+	for _, e := range note.ParseEntries(day.Format("2006-01-02"), string(data)) {
+		keys[entryKey(e.Timestamp, e.Content)] = true
+	}
+	return keys, nil
+}
+
+var (
+	// imageRegex matches markdown images: ![alt](target "title")
+	imageRegex = regexp.MustCompile(`(!\[[^\]]*\]\()([^)\s]+)((?:\s+"[^"]*")?\))`)
+	// wikiImageRegex matches Obsidian embeds: ![[image.png]] or ![[image.png|300]]
+	wikiImageRegex = regexp.MustCompile(`!\[\[([^\]|#]+)(?:[|#][^\]]*)?\]\]`)
+)
+
+// imageResolver finds the files referenced by imported notes and copies them
+type imageResolver struct {
+	root   string            // Source folder, searched by file name as a last resort
+	byName map[string]string // Lower-case base name -> path, built on first use
+}
+
+func newImageResolver(src string) *imageResolver {
+	root := src
+	if info, err := os.Stat(src); err == nil && !info.IsDir() {
+		root = filepath.Dir(src)
+	}
+	return &imageResolver{root: root}
+}
+
+// localize copies the images it.Content links to into the notebook and
+// rewrites the links, returning the new content and the number of images.
+// References that can't be found are reported and kept.
+func (r *imageResolver) localize(it item, opts Options, report *Report) (string, int) {
+	content := strings.TrimSpace(it.Content)
+	copied := 0
+
+	copyImage := func(path string) (string, bool) {
+		link, err := attachment.Import(opts.RootPath, opts.Layout, opts.LinkStyle, path, it.Time, opts.DryRun)
+		if err != nil {
+			report.Missing = append(report.Missing, fmt.Sprintf("%s (%v)", path, err))
+			return "", false
+		}
+		copied++
+		return link, true
+	}
+
+	// Markdown links first: the embeds rewritten below already point into the notebook
+	content = imageRegex.ReplaceAllStringFunc(content, func(match string) string {
+		m := imageRegex.FindStringSubmatch(match)
+		target := m[2]
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "data:") || strings.HasPrefix(target, "/attachments/") {
+			return match
+		}
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		path := r.find(it.Dir, target)
+		if path == "" {
+			report.Missing = append(report.Missing, target)
+			return match
+		}
+		link, ok := copyImage(path)
+		if !ok {
+			return match
+		}
+		return m[1] + link + m[3]
+	})
+
+	content = wikiImageRegex.ReplaceAllStringFunc(content, func(match string) string {
+		name := strings.TrimSpace(wikiImageRegex.FindStringSubmatch(match)[1])
+		path := r.find(it.Dir, name)
+		if path == "" {
+			report.Missing = append(report.Missing, name)
+			return match
+		}
+		link, ok := copyImage(path)
+		if !ok {
+			return match
+		}
+		return fmt.Sprintf("![%s](%s)", filepath.Base(name), link)
+	})
+
+	return content, copied
+}
+
+// find resolves a reference relative to dir, then by file name anywhere in
+// the source folder (Obsidian links by name; attachments often live elsewhere)
+func (r *imageResolver) find(dir, ref string) string {
+	path := filepath.FromSlash(ref)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path
+	}
+
+	if r.byName == nil {
+		r.byName = map[string]string{}
+		filepath.WalkDir(r.root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if p != r.root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			name := strings.ToLower(d.Name())
+			if _, ok := r.byName[name]; !ok {
+				r.byName[name] = p
+			}
+			return nil
+		})
+	}
+	return r.byName[strings.ToLower(filepath.Base(path))]
+}
+
+// sourceFiles lists the files to read: src itself, or the files in the
+// src folder (recursively, skipping hidden folders) that match
+func sourceFiles(src string, match func(name string) bool) ([]string, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{src}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != src && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", src, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// dateLayouts and timeLayouts are the formats accepted by parseDateTime
+var (
+	dateLayouts = []string{
+		"2006-01-02", "2006/01/02", "2006.01.02", "2006/1/2", "2006-1-2",
+		"2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05",
+		"2006/01/02 15:04", "2006/01/02 15:04:05",
+	}
+	timeLayouts = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM", "3:04:05 PM"}
+)
+
+// parseDateTime reads a date and an optional time of day in local time. The
+// date may carry the time itself ("2025-01-06 10:30", RFC 3339).
+func parseDateTime(date, clock string) (time.Time, error) {
+	date, clock = strings.TrimSpace(date), strings.TrimSpace(clock)
+
+	var day time.Time
+	var err error
+	if day, err = time.Parse(time.RFC3339, date); err == nil {
+		day = day.Local()
+	} else {
+		parsedDate := false
+		for _, layout := range dateLayouts {
+			if day, err = time.ParseInLocation(layout, date, time.Local); err == nil {
+				parsedDate = true
+				break
+			}
+		}
+		if !parsedDate {
+			return time.Time{}, fmt.Errorf("invalid date %q", date)
+		}
+	}
+	if clock == "" {
+		return day, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, strings.ToUpper(clock)); err == nil {
+			y, m, d := day.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", clock)
+}
@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+)
+
+func TestParseDateTime(t *testing.T) {
+	utc := time.Date(2025, 1, 6, 10, 30, 0, 0, time.UTC).Local()
+	tests := []struct {
+		date, clock string
+		want        time.Time
+		wantErr     bool
+	}{
+		{"2025-01-06", "", at(6, 0, 0), false},
+		{"2025/1/6", "", at(6, 0, 0), false},
+		{"2025.01.06", "10:30", at(6, 10, 30), false},
+		{"2025-01-06 10:30", "", at(6, 10, 30), false},
+		{"2025-01-06T10:30:59", "", time.Date(2025, 1, 6, 10, 30, 59, 0, time.Local), false},
+		{"2025-01-06T10:30:00Z", "", utc, false},
+		{" 2025-01-06 ", " 9:05 pm ", at(6, 21, 5), false},
+		{"2025-01-06", "21:05:30", at(6, 21, 5), false},
+		// The time column wins over a time in the date
+		{"2025-01-06 08:00", "10:30", at(6, 10, 30), false},
+		{"06/01/2025", "", time.Time{}, true},
+		{"2025-01-06", "noon", time.Time{}, true},
+		{"", "10:30", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDateTime(tt.date, tt.clock)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDateTime(%q, %q) error = %v, wantErr %v", tt.date, tt.clock, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDateTime(%q, %q) = %v, want %v", tt.date, tt.clock, got, tt.want)
+		}
+	}
+}
+
+func TestEntryKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   [2]string
+		equals bool
+	}{
+		{"same entry", [2]string{"10:30", "text"}, [2]string{"10:30", "text"}, true},
+		{"line endings", [2]string{"10:30", "a\r\nb"}, [2]string{"10:30", "a\nb"}, true},
+		{"surrounding space", [2]string{"10:30", "  text\n"}, [2]string{"10:30", "text"}, true},
+		{"other time", [2]string{"10:30", "text"}, [2]string{"10:31", "text"}, false},
+		{"other text", [2]string{"10:30", "text"}, [2]string{"10:30", "Text"}, false},
+	}
+	for _, tt := range tests {
+		if got := entryKey(tt.a[0], tt.a[1]) == entryKey(tt.b[0], tt.b[1]); got != tt.equals {
+			t.Errorf("%s: keys equal = %v, want %v", tt.name, got, tt.equals)
+		}
+	}
+}
+
+func writePNG(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunTwiceOnlyFindsDuplicates(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "my journal")
+	writePNG(t, filepath.Join(src, "img", "cat photo.png"))
+	log := "2025-01-06 10:30 first\n" +
+		"2025-01-06 10:45 multi\n  line\n" +
+		"2025-01-07 09:00 look ![cat](img/cat%20photo.png)\n" +
+		"2025-01-07 09:00 look ![missing](img/dog.png)\n"
+	if err := os.WriteFile(filepath.Join(src, "log.txt"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	opts := Options{RootPath: root, Layout: note.LayoutMonthly, LinkStyle: config.LinksRelative}
+
+	first, err := Run(Text, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Found != 4 || first.Imported != 4 || first.Duplicates != 0 || first.Attachments != 1 {
+		t.Errorf("first run = %+v", first)
+	}
+	if len(first.Missing) != 1 || first.Missing[0] != "img/dog.png" {
+		t.Errorf("missing = %q", first.Missing)
+	}
+	if first.Start != "2025-01-06" || first.End != "2025-01-07" {
+		t.Errorf("range = %s – %s", first.Start, first.End)
+	}
+
+	second, err := Run(Text, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Imported != 0 || second.Duplicates != 4 || second.Attachments != 0 {
+		t.Errorf("second run = %+v, want only duplicates", second)
+	}
+
+	data, err := os.ReadFile(note.LayoutMonthly.DailyFile(root, at(7, 0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "- [09:00] look"); n != 2 {
+		t.Errorf("found %d entries at 09:00, want 2:\n%s", n, data)
+	}
+
+	// A dry run against the imported notebook finds the same duplicates
+	opts.DryRun = true
+	dry, err := Run(Text, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Imported != 0 || dry.Duplicates != 4 {
+		t.Errorf("dry run = %+v", dry)
+	}
+}
+
+func TestDayOnePhotosInFolderWithSpaces(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "Day One Export")
+	writePNG(t, filepath.Join(src, "photos", "0123abcd.png"))
+	journal := `{"entries": [
+		{"creationDate": "2025-01-06T09:30:00Z", "timeZone": "UTC", "text": "Walk ![](dayone-moment://AAA)", "photos": [{"identifier": "AAA", "md5": "0123abcd", "type": "png"}]},
+		{"creationDate": "2025-01-06T10:00:00Z", "timeZone": "UTC", "text": "Gone ![](dayone-moment://BBB)", "photos": [{"identifier": "BBB", "md5": "ffff0000", "type": "jpeg"}]},
+		{"creationDate": "2025-01-06T11:00:00Z", "timeZone": "UTC", "text": "Unknown ![](dayone-moment://CCC)"}
+	]}`
+	if err := os.WriteFile(filepath.Join(src, "Journal.json"), []byte(journal), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	report, err := Run(JSON, filepath.Join(src, "Journal.json"), Options{RootPath: root, Layout: note.LayoutFlat, LinkStyle: config.LinksRelative})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 3 || report.Attachments != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	want := []string{"dayone-moment://CCC", "photos/ffff0000.jpeg"}
+	if len(report.Missing) != 2 || report.Missing[0] != want[0] || report.Missing[1] != want[1] {
+		t.Errorf("missing = %q, want %q", report.Missing, want)
+	}
+
+	// Filed under the wall-clock time in the entry's own time zone
+	data, err := os.ReadFile(note.LayoutFlat.DailyFile(root, at(6, 0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Walk ![](Attachment/") || strings.Contains(string(data), "Day One Export") {
+		t.Errorf("photo was not copied into the notebook:\n%s", data)
+	}
+}
@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dayOneExport is the part of a Day One "Journal.json" export we read
+type dayOneExport struct {
+	Entries []struct {
+		CreationDate time.Time `json:"creationDate"`
+		TimeZone     string    `json:"timeZone"`
+		Text         string    `json:"text"`
+		Photos       []struct {
+			Identifier string `json:"identifier"`
+			MD5        string `json:"md5"`
+			Type       string `json:"type"`
+		} `json:"photos"`
+	} `json:"entries"`
+}
+
+// dayOnePhotoRegex matches Day One's photo references in entry text
+var dayOnePhotoRegex = regexp.MustCompile(`dayone-moment://([A-Za-z0-9-]+)`)
+
+// parseJSON reads a Day One export (a Journal.json next to its photos/
+// folder, or the folder holding them) or a generic array of
+// {"date", "time", "text"} objects
+func parseJSON(src string) (parsed, error) {
+	var p parsed
+	files, err := sourceFiles(src, func(name string) bool { return strings.EqualFold(filepath.Ext(name), ".json") })
+	if err != nil {
+		return p, err
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return p, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		p.Files++
+
+		data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\uFEFF")))
+		if len(data) > 0 && data[0] == '[' {
+			err = parseJSONArray(path, data, &p)
+		} else {
+			err = parseDayOne(path, data, &p)
+		}
+		if err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func parseDayOne(path string, data []byte, p *parsed) error {
+	var export dayOneExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+
+	for i, e := range export.Entries {
+		if e.CreationDate.IsZero() {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s: entry %d has no creationDate", path, i+1))
+			continue
+		}
+		// Keep the wall-clock time the entry was written at, in its own time zone
+		t := e.CreationDate.Local()
+		if loc, err := time.LoadLocation(e.TimeZone); err == nil && e.TimeZone != "" {
+			w := e.CreationDate.In(loc)
+			t = time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), 0, 0, time.Local)
+		}
+
+		// Links relative to the export (resolved against the item's Dir) and
+		// escaped, since a markdown link target ends at the first space
+		photos := map[string]string{}
+		for _, ph := range e.Photos {
+			photos[ph.Identifier] = "photos/" + url.PathEscape(ph.MD5+"."+ph.Type)
+		}
+		text := dayOnePhotoRegex.ReplaceAllStringFunc(e.Text, func(match string) string {
+			id := dayOnePhotoRegex.FindStringSubmatch(match)[1]
+			photo, ok := photos[id]
+			if !ok {
+				p.Missing = append(p.Missing, match)
+				return match
+			}
+			return photo
+		})
+
+		p.Items = append(p.Items, item{Time: t, Content: text, Dir: dir})
+	}
+	return nil
+}
+
+// jsonKeys are the field names read from generic JSON entries, in order of preference
+var jsonKeys = struct{ date, time, text []string }{
+	date: []string{"date", "datetime", "timestamp", "created", "createdAt", "creationDate"},
+	time: []string{"time"},
+	text: []string{"text", "content", "note", "body"},
+}
+
+func parseJSONArray(path string, data []byte, p *parsed) error {
+	var entries []map[string]any
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	field := func(e map[string]any, keys []string) string {
+		for _, k := range keys {
+			if v, ok := e[k].(string); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	for i, e := range entries {
+		t, err := parseDateTime(field(e, jsonKeys.date), field(e, jsonKeys.time))
+		if err != nil {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s: entry %d: %v", path, i+1, err))
+			continue
+		}
+		p.Items = append(p.Items, item{Time: t, Content: field(e, jsonKeys.text), Dir: filepath.Dir(path)})
+	}
+	return nil
+}
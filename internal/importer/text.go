@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// stampedLineRegex matches "2025-01-06 10:30 text", "[2025-01-06 10:30:15] text", "2025/01/06 10:30 - text"
+	stampedLineRegex = regexp.MustCompile(`^\[?(\d{4}[-/.]\d{1,2}[-/.]\d{1,2})[ T]+(\d{1,2}:\d{2})(?::\d{2})?\]?\s*(?:[-–:|]\s*)?(.*)$`)
+	// dateLineRegex matches a line holding only a date ("2025-01-06", "# 2025-01-06"); the times below it belong to that day
+	dateLineRegex = regexp.MustCompile(`^(?:#+\s*)?\[?(\d{4}[-/.]\d{1,2}[-/.]\d{1,2})\]?\s*$`)
+	// clockLineRegex matches "10:30 text" or "[10:30] text" under a date line
+	clockLineRegex = regexp.MustCompile(`^(?:[-*+]\s+)?\[?(\d{1,2}:\d{2})(?::\d{2})?\]?\s*(?:[-–:|]\s*)?(.*)$`)
+)
+
+// parseText reads plain text logs (a file, or the .txt/.md/.log files in a
+// folder). An entry starts at a line with a date and time; lines without one
+// continue it. A line with only a date sets the day for "HH:MM text" lines.
+func parseText(src string) (parsed, error) {
+	var p parsed
+	files, err := sourceFiles(src, func(name string) bool {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".txt", ".md", ".log":
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return p, err
+	}
+
+	for _, path := range files {
+		if err := parseTextFile(path, &p); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func parseTextFile(path string, p *parsed) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+	p.Files++
+
+	var day string // From the last date line
+	var current *item
+	var pending []string // Lines of the current entry
+
+	flush := func() {
+		if current != nil {
+			current.Content = strings.Join(pending, "\n")
+			p.Items = append(p.Items, *current)
+		}
+		current, pending = nil, nil
+	}
+	start := func(t time.Time, text string) {
+		flush()
+		current = &item{Time: t, Dir: filepath.Dir(path)}
+		pending = []string{text}
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+
+		if m := stampedLineRegex.FindStringSubmatch(line); m != nil {
+			t, err := parseDateTime(m[1], m[2])
+			if err != nil {
+				p.Skipped = append(p.Skipped, fmt.Sprintf("%s:%d: %v", path, lineNo, err))
+				continue
+			}
+			start(t, m[3])
+			continue
+		}
+		if m := dateLineRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			day = m[1]
+			continue
+		}
+		if day != "" {
+			if m := clockLineRegex.FindStringSubmatch(line); m != nil {
+				t, err := parseDateTime(day, m[1])
+				if err != nil {
+					p.Skipped = append(p.Skipped, fmt.Sprintf("%s:%d: %v", path, lineNo, err))
+					continue
+				}
+				start(t, m[2])
+				continue
+			}
+		}
+
+		if current != nil {
+			pending = append(pending, line)
+		} else if strings.TrimSpace(line) != "" {
+			p.Skipped = append(p.Skipped, fmt.Sprintf("%s:%d: no timestamp", path, lineNo))
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSource writes a source file into a temporary folder and returns its path
+func writeSource(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// at returns a local time on 2025-01-06 (or the day given by d)
+func at(d, hour, min int) time.Time {
+	return time.Date(2025, 1, d, hour, min, 0, 0, time.Local)
+}
+
+type wantItem struct {
+	time    time.Time
+	content string
+}
+
+func checkItems(t *testing.T, got []item, want []wantItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		for _, it := range got {
+			t.Logf("got %v %q", it.Time, it.Content)
+		}
+		t.Fatalf("got %d items, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if !got[i].Time.Equal(w.time) || got[i].Content != w.content {
+			t.Errorf("item %d = %v %q, want %v %q", i, got[i].Time, got[i].Content, w.time, w.content)
+		}
+	}
+}
+
+func TestParseTextFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []wantItem
+		skipped int
+	}{
+		{
+			name:    "stamped lines",
+			content: "\uFEFF2025-01-06 10:30 first\n[2025-01-06 11:15:20] second\n2025/01/07 09:00 - third\n",
+			want:    []wantItem{{at(6, 10, 30), "first"}, {at(6, 11, 15), "second"}, {at(7, 9, 0), "third"}},
+		},
+		{
+			name:    "continuation lines",
+			content: "2025-01-06 10:30 list:\r\n- milk\r\n\r\n2025-01-06 12:00 lunch\r\n",
+			want:    []wantItem{{at(6, 10, 30), "list:\n- milk\n"}, {at(6, 12, 0), "lunch"}},
+		},
+		{
+			name:    "date lines set the day",
+			content: "# 2025-01-06\n10:30 standup\n- [14:00] review\n\n2025-01-07\n9:15 | dentist\n",
+			want:    []wantItem{{at(6, 10, 30), "standup"}, {at(6, 14, 0), "review\n"}, {at(7, 9, 15), "dentist"}},
+		},
+		{
+			name:    "text without a timestamp is skipped",
+			content: "notes from last week\n2025-01-06 10:30 kept\n",
+			want:    []wantItem{{at(6, 10, 30), "kept"}},
+			skipped: 1,
+		},
+		{
+			name:    "invalid times are skipped",
+			content: "2025-01-06 25:99 bad\n2025-13-01 10:00 bad too\n",
+			skipped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p parsed
+			if err := parseTextFile(writeSource(t, "log.txt", tt.content), &p); err != nil {
+				t.Fatal(err)
+			}
+			checkItems(t, p.Items, tt.want)
+			if len(p.Skipped) != tt.skipped {
+				t.Errorf("skipped %q, want %d lines", p.Skipped, tt.skipped)
+			}
+			if p.Files != 1 {
+				t.Errorf("Files = %d", p.Files)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package note
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CalendarDay is a day that has a daily file
+type CalendarDay struct {
+	Date    string `json:"date"`
+	Entries int    `json:"entries"`
+	Size    int64  `json:"size"` // Bytes of the daily file
+}
+
+// MonthSummary lists the days of a month (YYYY-MM) that have notes
+type MonthSummary struct {
+	Month   string        `json:"month"`
+	Days    []CalendarDay `json:"days"` // Oldest first
+	Entries int           `json:"entries"`
+	Size    int64         `json:"size"`
+}
+
+// MisplacedFile is a YYYY-MM-DD.md that is not where the notebook's layout
+// puts that day, so the app neither shows nor appends to it
+type MisplacedFile struct {
+	Date      string `json:"date"`
+	Path      string `json:"path"`
+	Expected  string `json:"expected"`  // Where the layout expects the file
+	Duplicate bool   `json:"duplicate"` // A file already exists at Expected
+}
+
+// Calendar is the month window asked for, plus the misplaced files of those months
+type Calendar struct {
+	From      string          `json:"from"` // YYYY-MM
+	To        string          `json:"to"`
+	Months    []MonthSummary  `json:"months"` // Every month of the window, oldest first
+	Misplaced []MisplacedFile `json:"misplaced"`
+}
+
+// maxCalendarMonths bounds the window of one Calendar call
+const maxCalendarMonths = 1200
+
+// dayFile is a daily file found by the scan
+type dayFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// entryCount is the cached number of entries of a file version
+type entryCount struct {
+	size    int64
+	modTime time.Time
+	entries int
+}
+
+// DateIndex is a cached scan of the daily files of one notebook. The folders
+// are walked once; Invalidate then re-checks only the days that changed, and
+// entry counts are kept per file until the file changes.
+type DateIndex struct {
+	rootPath string
+	layout   Layout
+
+	mu        sync.Mutex
+	scanned   bool
+	files     map[string]dayFile // Files where the layout expects them, by date
+	misplaced []MisplacedFile    // Sorted by date
+	counts    map[string]entryCount
+}
+
+// NewDateIndex creates an index for the notebook at rootPath; nothing is read
+// until it is first used
+func NewDateIndex(rootPath string, layout Layout) *DateIndex {
+	return &DateIndex{rootPath: rootPath, layout: layout, counts: map[string]entryCount{}}
+}
+
+// Invalidate re-checks the daily files of dates after they were created,
+// edited, moved or removed. Only the places a layout can put those days are
+// looked at, plus the misplaced files already known; a copy dropped into some
+// other folder is only found when a new DateIndex scans the notebook.
+func (x *DateIndex) Invalidate(dates []string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.scanned {
+		return // The first use scans everything anyway
+	}
+	for _, date := range dates {
+		x.refresh(date)
+	}
+	sortMisplaced(x.misplaced)
+}
+
+// refresh updates the files and misplaced entries of one date
+func (x *DateIndex) refresh(date string) {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return
+	}
+	expected := x.layout.DailyFile(x.rootPath, t)
+
+	_, hadFile := x.files[date]
+	if f, ok := statDayFile(expected); ok {
+		x.files[date] = f
+	} else if hadFile {
+		delete(x.files, date)
+		delete(x.counts, expected)
+	}
+
+	// Where the day was misplaced before, and where the other layouts put it
+	candidates := []string{}
+	misplaced := x.misplaced[:0]
+	for _, mf := range x.misplaced {
+		if mf.Date == date {
+			candidates = append(candidates, mf.Path)
+		} else {
+			misplaced = append(misplaced, mf)
+		}
+	}
+	for _, l := range []Layout{LayoutMonthly, LayoutYearly, LayoutFlat} {
+		candidates = append(candidates, l.DailyFile(x.rootPath, t))
+	}
+
+	_, duplicate := x.files[date]
+	seen := map[string]bool{filepath.Clean(expected): true}
+	for _, path := range candidates {
+		if seen[filepath.Clean(path)] {
+			continue
+		}
+		seen[filepath.Clean(path)] = true
+		if _, ok := statDayFile(path); ok {
+			misplaced = append(misplaced, MisplacedFile{Date: date, Path: path, Expected: expected, Duplicate: duplicate})
+		}
+	}
+	x.misplaced = misplaced
+}
+
+// statDayFile returns the dayFile at path, if it is a file
+func statDayFile(path string) (dayFile, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return dayFile{}, false
+	}
+	return dayFile{path: path, size: info.Size(), modTime: info.ModTime()}, true
+}
+
+// Dates returns the dates that have a daily file, newest first. Misplaced
+// files are left out.
+func (x *DateIndex) Dates() ([]string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.scan(); err != nil {
+		return nil, err
+	}
+	dates := make([]string, 0, len(x.files))
+	for date := range x.files {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+// Misplaced returns every daily file outside its layout folder, oldest first
+func (x *DateIndex) Misplaced() ([]MisplacedFile, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.scan(); err != nil {
+		return nil, err
+	}
+	return append([]MisplacedFile{}, x.misplaced...), nil
+}
+
+// Calendar summarises the months from..to (YYYY-MM, inclusive). Empty bounds
+// extend to the first or last month with notes.
+func (x *DateIndex) Calendar(from, to string) (*Calendar, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.scan(); err != nil {
+		return nil, err
+	}
+
+	if from == "" || to == "" {
+		first, last := x.monthRange()
+		if from == "" {
+			from = first
+		}
+		if to == "" {
+			to = last
+		}
+	}
+	cal := &Calendar{From: from, To: to, Months: []MonthSummary{}, Misplaced: []MisplacedFile{}}
+	if from == "" || to == "" {
+		return cal, nil // Empty notebook
+	}
+
+	start, err := time.ParseInLocation("2006-01", from, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month %q (use YYYY-MM)", from)
+	}
+	end, err := time.ParseInLocation("2006-01", to, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month %q (use YYYY-MM)", to)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("month %s is before %s", to, from)
+	}
+
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		if len(cal.Months) == maxCalendarMonths {
+			return nil, fmt.Errorf("too many months between %s and %s", from, to)
+		}
+		cal.Months = append(cal.Months, x.month(m))
+	}
+
+	for _, mf := range x.misplaced {
+		if month := mf.Date[:7]; month >= from && month <= to {
+			cal.Misplaced = append(cal.Misplaced, mf)
+		}
+	}
+	return cal, nil
+}
+
+// month summarises the days of the month starting at m
+func (x *DateIndex) month(m time.Time) MonthSummary {
+	summary := MonthSummary{Month: m.Format("2006-01"), Days: []CalendarDay{}}
+	for d := m; d.Month() == m.Month(); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		f, ok := x.files[date]
+		if !ok {
+			continue
+		}
+		day := CalendarDay{Date: date, Entries: x.entries(f), Size: f.size}
+		summary.Days = append(summary.Days, day)
+		summary.Entries += day.Entries
+		summary.Size += day.Size
+	}
+	return summary
+}
+
+// monthRange returns the first and last month with notes ("" when there are none)
+func (x *DateIndex) monthRange() (first, last string) {
+	for date := range x.files {
+		month := date[:7]
+		if first == "" || month < first {
+			first = month
+		}
+		if month > last {
+			last = month
+		}
+	}
+	return first, last
+}
+
+// entries counts the entries of f, reading it only when it changed since
+// it was last counted. An unreadable file counts as empty.
+func (x *DateIndex) entries(f dayFile) int {
+	if c, ok := x.counts[f.path]; ok && c.size == f.size && c.modTime.Equal(f.modTime) {
+		return c.entries
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", f.path, err)
+		return 0
+	}
+	n := len(ParseEntries(strings.TrimSuffix(filepath.Base(f.path), ".md"), string(data)))
+	x.counts[f.path] = entryCount{size: f.size, modTime: f.modTime, entries: n}
+	return n
+}
+
+// scan walks the notebook unless the last scan is still valid. Attachment
+// and hidden folders are skipped, as in WatchNotes.
+func (x *DateIndex) scan() error {
+	if x.scanned {
+		return nil
+	}
+
+	files := map[string]dayFile{}
+	var found []dayFile // Named like a daily file but in another folder
+	err := filepath.WalkDir(x.rootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == x.rootPath && os.IsNotExist(err) {
+				return filepath.SkipDir // No notes yet
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != x.rootPath && (d.Name() == "Attachment" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		m := dailyFileRegex.FindStringSubmatch(d.Name())
+		if m == nil {
+			return nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", m[1], time.Local)
+		if err != nil {
+			return nil // 2025-13-45.md
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+
+		f := dayFile{path: path, size: info.Size(), modTime: info.ModTime()}
+		if filepath.Clean(path) == filepath.Clean(x.layout.DailyFile(x.rootPath, t)) {
+			files[m[1]] = f
+		} else {
+			found = append(found, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan notes: %w", err)
+	}
+
+	misplaced := []MisplacedFile{}
+	for _, f := range found {
+		date := strings.TrimSuffix(filepath.Base(f.path), ".md")
+		t, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		_, duplicate := files[date]
+		misplaced = append(misplaced, MisplacedFile{
+			Date:      date,
+			Path:      f.path,
+			Expected:  x.layout.DailyFile(x.rootPath, t),
+			Duplicate: duplicate,
+		})
+	}
+	sortMisplaced(misplaced)
+
+	// Forget the counts of files that are gone
+	placed := map[string]bool{}
+	for _, f := range files {
+		placed[f.path] = true
+	}
+	for path := range x.counts {
+		if !placed[path] {
+			delete(x.counts, path)
+		}
+	}
+
+	x.files, x.misplaced, x.scanned = files, misplaced, true
+	return nil
+}
+
+// sortMisplaced orders misplaced files by date, then path
+func sortMisplaced(misplaced []MisplacedFile) {
+	sort.Slice(misplaced, func(i, j int) bool {
+		if misplaced[i].Date != misplaced[j].Date {
+			return misplaced[i].Date < misplaced[j].Date
+		}
+		return misplaced[i].Path < misplaced[j].Path
+	})
+}
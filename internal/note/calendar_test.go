@@ -0,0 +1,139 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDateIndexMisplaced(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) string {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	write("2025/06/2025-06-30.md", "- [09:00] a\n- [10:00] b\n")
+	duplicate := write("2025/2025-06-30.md", "- [09:00] yearly copy\n")
+	flat := write("2025-06-29.md", "- [09:00] flat\n")
+	inbox := write("inbox/2025-06-28.md", "- [09:00] dropped here\n")
+	write(".trash/2025-06-27.md", "- [09:00] trashed\n")
+	write("2025/06/Attachment/2025-06-26.md", "- [09:00] attached\n")
+	write("2025/13/2025-13-45.md", "- [09:00] no such day\n")
+
+	x := NewDateIndex(root, LayoutMonthly)
+	dates, err := x.Dates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2025-06-30"}; !reflect.DeepEqual(dates, want) {
+		t.Errorf("dates = %v, want %v", dates, want)
+	}
+
+	misplaced, err := x.Misplaced()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MisplacedFile{
+		{Date: "2025-06-28", Path: inbox, Expected: filepath.Join(root, "2025", "06", "2025-06-28.md")},
+		{Date: "2025-06-29", Path: flat, Expected: filepath.Join(root, "2025", "06", "2025-06-29.md")},
+		{Date: "2025-06-30", Path: duplicate, Expected: filepath.Join(root, "2025", "06", "2025-06-30.md"), Duplicate: true},
+	}
+	if !reflect.DeepEqual(misplaced, want) {
+		t.Errorf("misplaced = %+v\nwant %+v", misplaced, want)
+	}
+
+	cal, err := x.Calendar("2025-06", "2025-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Months) != 1 || len(cal.Months[0].Days) != 1 || cal.Months[0].Entries != 2 {
+		t.Errorf("months = %+v", cal.Months)
+	}
+	if len(cal.Misplaced) != 3 {
+		t.Errorf("calendar misplaced = %+v", cal.Misplaced)
+	}
+}
+
+func TestDateIndexInvalidate(t *testing.T) {
+	root := t.TempDir()
+	june := func(day string) string {
+		return filepath.Join(root, "2025", "06", "2025-06-"+day+".md")
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(june("30"), "- [09:00] a\n")
+	yearly := filepath.Join(root, "2025", "2025-06-30.md")
+	write(yearly, "- [09:00] yearly copy\n")
+	flat := filepath.Join(root, "2025-06-29.md")
+	write(flat, "- [09:00] flat\n")
+
+	x := NewDateIndex(root, LayoutMonthly)
+	check := func(wantDates []string, wantMisplaced []MisplacedFile) {
+		t.Helper()
+		dates, err := x.Dates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dates, wantDates) {
+			t.Errorf("dates = %v, want %v", dates, wantDates)
+		}
+		misplaced, err := x.Misplaced()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(misplaced, wantMisplaced) {
+			t.Errorf("misplaced = %+v\nwant %+v", misplaced, wantMisplaced)
+		}
+	}
+	check([]string{"2025-06-30"}, []MisplacedFile{
+		{Date: "2025-06-29", Path: flat, Expected: june("29")},
+		{Date: "2025-06-30", Path: yearly, Expected: june("30"), Duplicate: true},
+	})
+
+	// New files are only seen for the dates invalidated
+	write(june("01"), "- [09:00] new\n")
+	write(june("02"), "- [09:00] not announced\n")
+	x.Invalidate([]string{"2025-06-01"})
+	check([]string{"2025-06-30", "2025-06-01"}, []MisplacedFile{
+		{Date: "2025-06-29", Path: flat, Expected: june("29")},
+		{Date: "2025-06-30", Path: yearly, Expected: june("30"), Duplicate: true},
+	})
+
+	// The misplaced file moved where the layout expects it
+	if err := os.Rename(flat, june("29")); err != nil {
+		t.Fatal(err)
+	}
+	// The expected file removed, leaving the yearly copy
+	if err := os.Remove(june("30")); err != nil {
+		t.Fatal(err)
+	}
+	x.Invalidate([]string{"2025-06-29", "2025-06-30"})
+	check([]string{"2025-06-29", "2025-06-01"}, []MisplacedFile{
+		{Date: "2025-06-30", Path: yearly, Expected: june("30")},
+	})
+
+	// A copy appearing where another layout puts the day is found
+	flat30 := filepath.Join(root, "2025-06-30.md")
+	write(flat30, "- [09:00] flat copy\n")
+	x.Invalidate([]string{"2025-06-30"})
+	check([]string{"2025-06-29", "2025-06-01"}, []MisplacedFile{
+		{Date: "2025-06-30", Path: flat30, Expected: june("30")},
+		{Date: "2025-06-30", Path: yearly, Expected: june("30")},
+	})
+}
@@ -0,0 +1,54 @@
+package note
+
+import "strings"
+
+// ReadEntries returns the notes written between start and end (inclusive,
+// YYYY-MM-DD), oldest first. Unlike GetNotesByDateRange it keeps every line
+// of multi-line notes, so it suits exports rather than one-line listings.
+func ReadEntries(rootPath string, layout Layout, start, end string) ([]NoteEntry, error) {
+	days, err := GetDailyNotes(rootPath, layout, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []NoteEntry
+	// GetDailyNotes is newest first
+	for i := len(days) - 1; i >= 0; i-- {
+		entries = append(entries, ParseEntries(days[i].Date, days[i].Content)...)
+	}
+	return entries, nil
+}
+
+// ParseEntries splits a daily file into its "- [HH:MM] ..." entries. Lines
+// that don't start an entry continue the previous one; text before the first
+// entry (a heading added by hand) becomes an entry without a timestamp.
+func ParseEntries(date, content string) []NoteEntry {
+	var entries []NoteEntry
+	var current *NoteEntry
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := noteLineRegex.FindStringSubmatch(line); m != nil {
+			entries = append(entries, NoteEntry{Timestamp: m[1], Content: m[2], Date: date, RawLine: line})
+			current = &entries[len(entries)-1]
+			continue
+		}
+		if current == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			entries = append(entries, NoteEntry{Content: line, Date: date, RawLine: line})
+			current = &entries[len(entries)-1]
+			continue
+		}
+		current.Content += "\n" + line
+		current.RawLine += "\n" + line
+	}
+
+	// Blank lines between entries belong to neither
+	for i := range entries {
+		entries[i].Content = strings.TrimRight(entries[i].Content, "\n ")
+		entries[i].RawLine = strings.TrimRight(entries[i].RawLine, "\n")
+	}
+	return entries
+}
@@ -0,0 +1,75 @@
+package note
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEntries(t *testing.T) {
+	const date = "2025-06-30"
+	tests := []struct {
+		name    string
+		content string
+		want    []NoteEntry
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "single entries",
+			content: "- [09:00] first\n- [10:30] second\n",
+			want: []NoteEntry{
+				{Timestamp: "09:00", Content: "first", Date: date, RawLine: "- [09:00] first"},
+				{Timestamp: "10:30", Content: "second", Date: date, RawLine: "- [10:30] second"},
+			},
+		},
+		{
+			name:    "multi-line entry",
+			content: "- [09:00] list:\n  - milk\n  - eggs\n\n- [10:30] done\n",
+			want: []NoteEntry{
+				{Timestamp: "09:00", Content: "list:\n  - milk\n  - eggs", Date: date, RawLine: "- [09:00] list:\n  - milk\n  - eggs"},
+				{Timestamp: "10:30", Content: "done", Date: date, RawLine: "- [10:30] done"},
+			},
+		},
+		{
+			name:    "text before the first entry",
+			content: "\n# Monday\nplanning day\n- [09:00] started\n",
+			want: []NoteEntry{
+				{Content: "# Monday\nplanning day", Date: date, RawLine: "# Monday\nplanning day"},
+				{Timestamp: "09:00", Content: "started", Date: date, RawLine: "- [09:00] started"},
+			},
+		},
+		{
+			name:    "CRLF line endings",
+			content: "- [09:00] from windows\r\n  second line\r\n- [10:30] next\r\n",
+			want: []NoteEntry{
+				{Timestamp: "09:00", Content: "from windows\n  second line", Date: date, RawLine: "- [09:00] from windows\n  second line"},
+				{Timestamp: "10:30", Content: "next", Date: date, RawLine: "- [10:30] next"},
+			},
+		},
+		{
+			name:    "no trailing newline",
+			content: "- [23:59] last",
+			want: []NoteEntry{
+				{Timestamp: "23:59", Content: "last", Date: date, RawLine: "- [23:59] last"},
+			},
+		},
+		{
+			name:    "lines that only look like entries",
+			content: "- [9:00] short hour\n-[09:00] no space\n",
+			want: []NoteEntry{
+				{Content: "- [9:00] short hour\n-[09:00] no space", Date: date, RawLine: "- [9:00] short hour\n-[09:00] no space"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseEntries(date, tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEntries(%q)\n got %#v\nwant %#v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package note
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Layout decides where a day's file lives inside a notebook's root
+type Layout string
+
+const (
+	LayoutMonthly Layout = "monthly" // RootPath/YYYY/MM/YYYY-MM-DD.md (default)
+	LayoutYearly  Layout = "yearly"  // RootPath/YYYY/YYYY-MM-DD.md
+	LayoutFlat    Layout = "flat"    // RootPath/YYYY-MM-DD.md
+)
+
+// Valid reports whether l is a known layout. The empty layout means monthly.
+func (l Layout) Valid() bool {
+	switch l {
+	case "", LayoutMonthly, LayoutYearly, LayoutFlat:
+		return true
+	}
+	return false
+}
+
+// Dir returns the folder holding the daily file for t
+func (l Layout) Dir(rootPath string, t time.Time) string {
+	switch l {
+	case LayoutFlat:
+		return rootPath
+	case LayoutYearly:
+		return filepath.Join(rootPath, t.Format("2006"))
+	default:
+		return filepath.Join(rootPath, t.Format("2006"), t.Format("01"))
+	}
+}
+
+// DailyFile returns the path of the daily file for t
+func (l Layout) DailyFile(rootPath string, t time.Time) string {
+	return filepath.Join(l.Dir(rootPath, t), fmt.Sprintf("%s.md", t.Format("2006-01-02")))
+}
@@ -2,64 +2,123 @@ package note
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"io/fs"
 	"os"
-	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"time"
+
+	"t-log/internal/fsutil"
 )
 
-// SaveNote appends a note to the file at RootPath/YYYY/MM/YYYY-MM-DD.md
-func SaveNote(rootPath, content string) error {
+var (
+	// ErrNotWritten marks save errors that happened before anything was
+	// written (the folder or file could not be created, opened or locked), so
+	// saving again later cannot duplicate the note
+	ErrNotWritten = errors.New("note was not written")
+
+	// ErrModified means the entry was written but another program changed
+	// the file at the same time; the note may or may not have been kept
+	ErrModified = errors.New("modified by another program while saving")
+)
+
+// unwrittenError is an error matching both ErrNotWritten and its cause,
+// with the cause's message
+type unwrittenError struct {
+	err error
+}
+
+func (e unwrittenError) Error() string   { return e.err.Error() }
+func (e unwrittenError) Unwrap() []error { return []error{ErrNotWritten, e.err} }
+
+// SaveNote appends a note to today's file, RootPath/YYYY/MM/YYYY-MM-DD.md for
+// the default monthly layout. It returns where the entry was written so it can
+// be undone with RemoveEntry.
+func SaveNote(rootPath string, layout Layout, content string) (SavedEntry, error) {
+	return SaveNoteAt(rootPath, layout, content, time.Now())
+}
+
+// SaveNoteAt appends a note captured at now to that day's file, stamped with
+// its time. Used to deliver queued notes under their original timestamp.
+func SaveNoteAt(rootPath string, layout Layout, content string, now time.Time) (SavedEntry, error) {
 	if content == "" {
-		return nil
+		return SavedEntry{}, nil
 	}
 
-	now := time.Now()
-	year := now.Format("2006")
-	month := now.Format("01")
-	day := now.Format("2006-01-02")
-
-	// Directory structure: RootPath/YYYY/MM
-	dirPath := filepath.Join(rootPath, year, month)
+	// Directory structure depends on the notebook layout, e.g. RootPath/YYYY/MM
+	dirPath := layout.Dir(rootPath, now)
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return SavedEntry{}, unwrittenError{fmt.Errorf("failed to create directory: %w", err)}
 	}
 
-	filename := fmt.Sprintf("%s.md", day)
-	filePath := filepath.Join(dirPath, filename)
+	filePath := layout.DailyFile(rootPath, now)
 
 	// Format: - [HH:MM] Content
 	timeStr := now.Format("15:04")
 	line := fmt.Sprintf("- [%s] %s\n", timeStr, content)
 
-	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Other writers (a second instance, CLI captures) wait on the lock; an
+	// editor that replaced the file while we waited is detected by OpenLocked
+	f, err := fsutil.OpenLocked(filePath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return SavedEntry{}, unwrittenError{fmt.Errorf("failed to open file: %w", err)}
 	}
 	defer f.Close()
 
-	if _, err := f.WriteString(line); err != nil {
-		return fmt.Errorf("failed to write note: %w", err)
+	// Appends always land at the end, so the current size is the entry's offset
+	info, err := f.Stat()
+	if err != nil {
+		return SavedEntry{}, unwrittenError{fmt.Errorf("failed to stat file: %w", err)}
+	}
+	offset := info.Size()
+
+	// A file edited by hand may not end with a newline; the entry must start a line
+	prefix := ""
+	if offset > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, offset-1); err != nil {
+			return SavedEntry{}, unwrittenError{fmt.Errorf("failed to read file: %w", err)}
+		}
+		if last[0] != '\n' {
+			prefix = "\n"
+			offset++
+		}
+	}
+
+	// From here on part of the entry may be on disk
+	if _, err := f.WriteString(prefix + line); err != nil {
+		return SavedEntry{}, fmt.Errorf("failed to write note: %w", err)
 	}
 
-	return nil
+	// Programs that don't lock can still truncate or rewrite the file under us
+	written := make([]byte, len(line))
+	if _, err := f.ReadAt(written, offset); err != nil || string(written) != line {
+		return SavedEntry{}, fmt.Errorf("%s was %w, check the note was kept", filePath, ErrModified)
+	}
+
+	return SavedEntry{
+		ID:      newEntryID(now),
+		File:    filePath,
+		Offset:  offset,
+		Text:    line,
+		Prefix:  prefix,
+		Content: content,
+		SavedAt: now,
+	}, nil
 }
 
 // GetRecentNotes reads notes from the last n days
-func GetRecentNotes(rootPath string, n int) ([]DailyNote, error) {
+func GetRecentNotes(rootPath string, layout Layout, n int) ([]DailyNote, error) {
 	now := time.Now()
 	// Start date is Today - (n-1) days
 	startDate := now.AddDate(0, 0, -(n - 1))
-	return GetDailyNotes(rootPath, startDate.Format("2006-01-02"), now.Format("2006-01-02"))
+	return GetDailyNotes(rootPath, layout, startDate.Format("2006-01-02"), now.Format("2006-01-02"))
 }
 
 // GetNotesByDateRange reads notes within a start and end date range (inclusive)
 // start, end format: YYYY-MM-DD
-func GetNotesByDateRange(rootPath, start, end string) ([]NoteEntry, error) {
+func GetNotesByDateRange(rootPath string, layout Layout, start, end string) ([]NoteEntry, error) {
 	startDate, err := time.Parse("2006-01-02", start)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start date: %w", err)
@@ -78,12 +137,8 @@ func GetNotesByDateRange(rootPath, start, end string) ([]NoteEntry, error) {
 
 	current := endDate
 	for !current.Before(startDate) {
-		year := current.Format("2006")
-		month := current.Format("01")
 		day := current.Format("2006-01-02")
-
-		filename := fmt.Sprintf("%s.md", day)
-		filePath := filepath.Join(rootPath, year, month, filename)
+		filePath := layout.DailyFile(rootPath, current)
 
 		dayEntries, err := parseNoteFile(filePath, day)
 		if err == nil {
@@ -103,7 +158,7 @@ func GetNotesByDateRange(rootPath, start, end string) ([]NoteEntry, error) {
 
 // GetDailyNotes reads full file contents within a start and end date range (inclusive)
 // Returns parsed DailyNote structs
-func GetDailyNotes(rootPath, start, end string) ([]DailyNote, error) {
+func GetDailyNotes(rootPath string, layout Layout, start, end string) ([]DailyNote, error) {
 	startDate, err := time.Parse("2006-01-02", start)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start date: %w", err)
@@ -118,12 +173,8 @@ func GetDailyNotes(rootPath, start, end string) ([]DailyNote, error) {
 	// Iterate from end date down to start date (newest first)
 	current := endDate
 	for !current.Before(startDate) {
-		year := current.Format("2006")
-		month := current.Format("01")
 		day := current.Format("2006-01-02")
-
-		filename := fmt.Sprintf("%s.md", day)
-		filePath := filepath.Join(rootPath, year, month, filename)
+		filePath := layout.DailyFile(rootPath, current)
 
 		contentBytes, err := os.ReadFile(filePath)
 		if err == nil {
@@ -177,23 +228,19 @@ func parseNoteFile(path, date string) ([]NoteEntry, error) {
 
 // OpenDailyNote opens the daily note file in the system default editor
 // Implements US3 logic
-func OpenDailyNote(rootPath string) error {
+func OpenDailyNote(rootPath string, layout Layout) error {
 	// We need to find today's file. If it doesn't exist, we should probably create it first?
 	// Or just open the directory if file missing?
 	// Spec says "launch current file". Let's ensure it exists.
 
 	now := time.Now()
-	year := now.Format("2006")
-	month := now.Format("01")
-	day := now.Format("2006-01-02")
 
-	dirPath := filepath.Join(rootPath, year, month)
+	dirPath := layout.Dir(rootPath, now)
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		return err
 	}
 
-	filename := fmt.Sprintf("%s.md", day)
-	filePath := filepath.Join(dirPath, filename)
+	filePath := layout.DailyFile(rootPath, now)
 
 	// Ensure file exists
 	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE, 0644)
@@ -206,42 +253,9 @@ func OpenDailyNote(rootPath string) error {
 	return openFileInOS(filePath)
 }
 
-// ListNoteDates returns a list of all available note dates (YYYY-MM-DD)
-func ListNoteDates(rootPath string) ([]string, error) {
-	var dates []string
-
-	// Walk the root path
-	err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			// Skip hidden directories if any, or Attachment directory
-			if d.Name() == "Attachment" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Check if file matches YYYY-MM-DD.md
-		name := d.Name()
-		if strings.HasSuffix(name, ".md") {
-			// Simple validation: length is 10+3=13 chars?
-			// Better: regex check
-			datePart := strings.TrimSuffix(name, ".md")
-			if _, err := time.Parse("2006-01-02", datePart); err == nil {
-				dates = append(dates, datePart)
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Sort reverse chronological
-	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
-
-	return dates, nil
+// ListNoteDates returns the dates (YYYY-MM-DD) that have a daily file where
+// layout puts it, newest first. Use a DateIndex to avoid walking the folders
+// on every call.
+func ListNoteDates(rootPath string, layout Layout) ([]string, error) {
+	return NewDateIndex(rootPath, layout).Dates()
 }
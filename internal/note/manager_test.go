@@ -0,0 +1,160 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	concurrentWriters = 6
+	notesPerWriter    = 20
+)
+
+// saveAll saves notesPerWriter notes tagged with writer, all at the same time
+func saveAll(root, writer string, now time.Time) error {
+	for i := 0; i < notesPerWriter; i++ {
+		if _, err := SaveNoteAt(root, LayoutMonthly, fmt.Sprintf("%s note %d", writer, i), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestHelperSaveNotes is not a real test: TestSaveNoteAtConcurrent runs the
+// test binary again with it to get writers in another process
+func TestHelperSaveNotes(t *testing.T) {
+	root := os.Getenv("TLOG_HELPER_ROOT")
+	if root == "" {
+		t.Skip("helper process only")
+	}
+	unix, _ := strconv.ParseInt(os.Getenv("TLOG_HELPER_TIME"), 10, 64)
+	if err := saveAll(root, os.Getenv("TLOG_HELPER_WRITER"), time.Unix(unix, 0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveNoteAtConcurrent(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2025, 6, 30, 9, 15, 0, 0, time.Local)
+
+	var writers []string
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentWriters+2)
+
+	// Other processes hold the same lock through a different handle
+	for p := 0; p < 2; p++ {
+		writer := fmt.Sprintf("process-%d", p)
+		writers = append(writers, writer)
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperSaveNotes$")
+		cmd.Env = append(os.Environ(),
+			"TLOG_HELPER_ROOT="+root,
+			"TLOG_HELPER_WRITER="+writer,
+			"TLOG_HELPER_TIME="+strconv.FormatInt(now.Unix(), 10),
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("%s: %v\n%s", writer, err, out)
+			}
+		}()
+	}
+
+	for g := 0; g < concurrentWriters; g++ {
+		writer := fmt.Sprintf("goroutine-%d", g)
+		writers = append(writers, writer)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := saveAll(root, writer, now); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(LayoutMonthly.DailyFile(root, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "\n") {
+		t.Errorf("file does not end with a newline")
+	}
+
+	// Every note on its own, whole line, exactly once
+	seen := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		seen[line]++
+	}
+	want := len(writers) * notesPerWriter
+	if len(seen) != want {
+		t.Errorf("found %d distinct lines, want %d", len(seen), want)
+	}
+	for _, writer := range writers {
+		for i := 0; i < notesPerWriter; i++ {
+			line := fmt.Sprintf("- [09:15] %s note %d", writer, i)
+			if seen[line] != 1 {
+				t.Errorf("%q appears %d times", line, seen[line])
+			}
+		}
+	}
+}
+
+func TestSaveNoteAtStartsANewLine(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2025, 6, 30, 9, 15, 0, 0, time.Local)
+	path := LayoutMonthly.DailyFile(root, now)
+	if err := os.MkdirAll(LayoutMonthly.Dir(root, now), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Edited by hand, no trailing newline
+	if err := os.WriteFile(path, []byte("- [08:00] by hand"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := SaveNoteAt(root, LayoutMonthly, "captured", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- [08:00] by hand\n- [09:15] captured\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	if got := string(data[saved.Offset:]); got != saved.Text {
+		t.Errorf("entry at offset %d = %q, want %q", saved.Offset, got, saved.Text)
+	}
+}
+
+func TestSaveNoteAtNotWritten(t *testing.T) {
+	// The notebook root is a file, so its folders can't be created
+	root := filepath.Join(t.TempDir(), "offline")
+	if err := os.WriteFile(root, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := SaveNoteAt(root, LayoutMonthly, "kept for later", time.Now())
+	if !errors.Is(err, ErrNotWritten) {
+		t.Fatalf("err = %v, want ErrNotWritten", err)
+	}
+	if errors.Is(err, ErrModified) {
+		t.Errorf("unwritten note reported as modified")
+	}
+	if !strings.HasPrefix(err.Error(), "failed to create directory") {
+		t.Errorf("message = %q", err)
+	}
+}
@@ -9,6 +9,11 @@ import (
 	"time"
 )
 
+// OpenFile opens path with the system default application
+func OpenFile(path string) error {
+	return openFileInOS(path)
+}
+
 func openFileInOS(path string) error {
 	var cmd *exec.Cmd
 
@@ -52,19 +57,15 @@ func OpenNoteAt(path string, lineNo int) error {
 
 // OpenDateNote opens the markdown file for a specific date
 // dateStr should be in "YYYY-MM-DD" format
-func OpenDateNote(rootPath, dateStr string) error {
+func OpenDateNote(rootPath string, layout Layout, dateStr string) error {
 	// Validate date format
 	t, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		return fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
 	}
 
-	year := t.Format("2006")
-	month := t.Format("01")
-	filename := t.Format("2006-01-02") + ".md"
-
-	// Path: root/2006/01/2006-01-02.md
-	filePath := filepath.Join(rootPath, year, month, filename)
+	// Path: root/2006/01/2006-01-02.md for the monthly layout
+	filePath := layout.DailyFile(rootPath, t)
 
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
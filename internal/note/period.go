@@ -0,0 +1,22 @@
+package note
+
+import "time"
+
+// Day returns local midnight of t's day
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns local midnight of the Monday of t's week
+func StartOfWeek(t time.Time) time.Time {
+	// Sunday is day 0 but ends the week
+	offset := (int(t.Weekday()) + 6) % 7
+	return Day(t).AddDate(0, 0, -offset)
+}
+
+// StartOfMonth returns local midnight of the first day of t's month
+func StartOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,94 @@
+package note
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Zones below must load on machines without a zoneinfo database
+)
+
+func TestPeriodStart(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		t           time.Time
+		week, month time.Time
+	}{
+		{
+			name:  "monday midnight starts its own week",
+			t:     time.Date(2025, 6, 30, 0, 0, 0, 0, newYork),
+			week:  time.Date(2025, 6, 30, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 6, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:  "monday just before midnight",
+			t:     time.Date(2025, 6, 30, 23, 59, 59, 0, newYork),
+			week:  time.Date(2025, 6, 30, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 6, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:  "sunday just before midnight ends the previous week",
+			t:     time.Date(2025, 6, 29, 23, 59, 59, 0, newYork),
+			week:  time.Date(2025, 6, 23, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 6, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 03:30 UTC on Monday is still Sunday evening in New York
+			name:  "local sunday that is monday in UTC",
+			t:     time.Date(2025, 6, 30, 3, 30, 0, 0, time.UTC).In(newYork),
+			week:  time.Date(2025, 6, 23, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 6, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 16:00 UTC on Saturday May 31 is already Sunday June 1 in Shanghai
+			name:  "local sunday that is saturday in UTC",
+			t:     time.Date(2025, 5, 31, 16, 0, 0, 0, time.UTC).In(shanghai),
+			week:  time.Date(2025, 5, 26, 0, 0, 0, 0, shanghai),
+			month: time.Date(2025, 6, 1, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "week across the new year",
+			t:     time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai),
+			week:  time.Date(2024, 12, 30, 0, 0, 0, 0, shanghai),
+			month: time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai),
+		},
+		{
+			// Clocks go forward at 02:00, the week started in standard time
+			name:  "sunday of the spring DST change",
+			t:     time.Date(2025, 3, 9, 23, 59, 0, 0, newYork),
+			week:  time.Date(2025, 3, 3, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 3, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			// Clocks go back at 02:00, the week started in daylight time
+			name:  "sunday of the autumn DST change",
+			t:     time.Date(2025, 11, 2, 0, 30, 0, 0, newYork),
+			week:  time.Date(2025, 10, 27, 0, 0, 0, 0, newYork),
+			month: time.Date(2025, 11, 1, 0, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(what string, got, want time.Time) {
+				t.Helper()
+				if !got.Equal(want) || got.Location() != want.Location() {
+					t.Errorf("%s = %v, want %v", what, got, want)
+				}
+				if h, m, s := got.Clock(); h != 0 || m != 0 || s != 0 {
+					t.Errorf("%s = %v, not local midnight", what, got)
+				}
+			}
+			check("Day", Day(tt.t), time.Date(tt.t.Year(), tt.t.Month(), tt.t.Day(), 0, 0, 0, 0, tt.t.Location()))
+			check("StartOfWeek", StartOfWeek(tt.t), tt.week)
+			check("StartOfMonth", StartOfMonth(tt.t), tt.month)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package note
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxStatsTags is how many tags Stats lists
+const maxStatsTags = 50
+
+// DayStats is the activity of one day
+type DayStats struct {
+	Date            string `json:"date"`
+	Entries         int    `json:"entries"`
+	Words           int    `json:"words"`
+	Attachments     int    `json:"attachments"`
+	AttachmentBytes int64  `json:"attachmentBytes"`
+}
+
+// Streak is a run of consecutive days with notes
+type Streak struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Days  int    `json:"days"`
+}
+
+// TagTrend is how often a tag was used, overall and per month (YYYY-MM)
+type TagTrend struct {
+	Tag     string         `json:"tag"`
+	Total   int            `json:"total"`
+	ByMonth map[string]int `json:"byMonth"`
+}
+
+// Stats summarises the notes between Start and End
+type Stats struct {
+	Start           string     `json:"start"`
+	End             string     `json:"end"`
+	Days            []DayStats `json:"days"` // Days with notes, oldest first
+	ActiveDays      int        `json:"activeDays"`
+	Entries         int        `json:"entries"`
+	Words           int        `json:"words"`
+	Hours           [24]int    `json:"hours"`    // Entries per hour of the day
+	Weekdays        [7]int     `json:"weekdays"` // Entries per weekday, Monday first
+	BusiestHour     int        `json:"busiestHour"`
+	CurrentStreak   Streak     `json:"currentStreak"` // Ending today or yesterday
+	LongestStreak   Streak     `json:"longestStreak"`
+	Tags            []TagTrend `json:"tags"` // Most used first
+	Attachments     int        `json:"attachments"`
+	AttachmentBytes int64      `json:"attachmentBytes"`
+}
+
+// fileStats is what the cache keeps per daily file
+type fileStats struct {
+	modTime time.Time
+	size    int64
+	day     DayStats
+	hours   [24]int
+	tags    map[string]int
+}
+
+// StatsCache computes Stats for the notebook of a DateIndex, re-reading only
+// the daily files that changed since they were last counted
+type StatsCache struct {
+	index *DateIndex
+
+	mu    sync.Mutex
+	files map[string]fileStats
+}
+
+// NewStatsCache creates an empty cache for the notebook indexed by index
+func NewStatsCache(index *DateIndex) *StatsCache {
+	return &StatsCache{index: index, files: map[string]fileStats{}}
+}
+
+// Invalidate forgets what is known about dates (files changed on disk). The
+// index has to be invalidated too for new dates to be listed.
+func (c *StatsCache) Invalidate(dates []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, date := range dates {
+		delete(c.files, date)
+	}
+}
+
+// Stats computes the statistics for start..end (YYYY-MM-DD, inclusive).
+// Empty bounds extend to the first or last note.
+func (c *StatsCache) Stats(start, end string) (*Stats, error) {
+	dates, err := c.index.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := &Stats{Start: start, End: end, Days: []DayStats{}, Tags: []TagTrend{}}
+	tags := map[string]*TagTrend{}
+
+	// Dates are newest first
+	for i := len(dates) - 1; i >= 0; i-- {
+		date := dates[i]
+		if (start != "" && date < start) || (end != "" && date > end) {
+			continue
+		}
+		fs, ok, err := c.fileStats(date)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || fs.day.Entries == 0 {
+			continue
+		}
+
+		stats.Days = append(stats.Days, fs.day)
+		stats.Entries += fs.day.Entries
+		stats.Words += fs.day.Words
+		stats.Attachments += fs.day.Attachments
+		stats.AttachmentBytes += fs.day.AttachmentBytes
+		for h, n := range fs.hours {
+			stats.Hours[h] += n
+		}
+		if t, err := time.Parse("2006-01-02", date); err == nil {
+			stats.Weekdays[(int(t.Weekday())+6)%7] += fs.day.Entries
+		}
+		for tag, n := range fs.tags {
+			tt, ok := tags[tag]
+			if !ok {
+				tt = &TagTrend{Tag: tag, ByMonth: map[string]int{}}
+				tags[tag] = tt
+			}
+			tt.Total += n
+			tt.ByMonth[date[:7]] += n
+		}
+	}
+
+	stats.ActiveDays = len(stats.Days)
+	if len(stats.Days) > 0 {
+		if stats.Start == "" {
+			stats.Start = stats.Days[0].Date
+		}
+		if stats.End == "" {
+			stats.End = stats.Days[len(stats.Days)-1].Date
+		}
+	}
+	for h, n := range stats.Hours {
+		if n > stats.Hours[stats.BusiestHour] {
+			stats.BusiestHour = h
+		}
+	}
+	stats.CurrentStreak, stats.LongestStreak = streaks(stats.Days, time.Now().Format("2006-01-02"))
+
+	for _, tt := range tags {
+		stats.Tags = append(stats.Tags, *tt)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].Total != stats.Tags[j].Total {
+			return stats.Tags[i].Total > stats.Tags[j].Total
+		}
+		return stats.Tags[i].Tag < stats.Tags[j].Tag
+	})
+	if len(stats.Tags) > maxStatsTags {
+		stats.Tags = stats.Tags[:maxStatsTags]
+	}
+	return stats, nil
+}
+
+// fileStats returns the cached counts for date, reading the daily file when
+// it is new or changed. ok is false when the file doesn't exist.
+func (c *StatsCache) fileStats(date string) (fileStats, bool, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return fileStats{}, false, nil
+	}
+	path := c.index.layout.DailyFile(c.index.rootPath, day)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		delete(c.files, date)
+		return fileStats{}, false, nil
+	}
+	if err != nil {
+		return fileStats{}, false, err
+	}
+
+	if fs, ok := c.files[date]; ok && fs.modTime.Equal(info.ModTime()) && fs.size == info.Size() {
+		return fs, true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileStats{}, false, err
+	}
+	fs := countFile(c.index.rootPath, path, date, string(data))
+	fs.modTime, fs.size = info.ModTime(), info.Size()
+	c.files[date] = fs
+	return fs, true, nil
+}
+
+// attachmentLinkRegex matches link targets inside an Attachment folder, in
+// either link style: /attachments/YYYY/MM/Attachment/x.png or Attachment/x.png
+var attachmentLinkRegex = regexp.MustCompile(`\]\(([^)\s]*Attachment/[^)\s]+)\)`)
+
+// countFile counts the entries, words, hours, tags and attachments of a daily file
+func countFile(rootPath, path, date, content string) fileStats {
+	fs := fileStats{day: DayStats{Date: date}, tags: map[string]int{}}
+	seen := map[string]bool{}
+
+	for _, e := range ParseEntries(date, content) {
+		fs.day.Entries++
+		fs.day.Words += CountWords(e.Content)
+		if t, err := time.Parse("15:04", e.Timestamp); err == nil {
+			fs.hours[t.Hour()]++
+		}
+		for _, tag := range Tags(e.Content) {
+			fs.tags[tag]++
+		}
+
+		for _, m := range attachmentLinkRegex.FindAllStringSubmatch(e.Content, -1) {
+			file := attachmentFile(rootPath, path, m[1])
+			if file == "" || seen[file] {
+				continue
+			}
+			seen[file] = true
+			fs.day.Attachments++
+			if info, err := os.Stat(file); err == nil {
+				fs.day.AttachmentBytes += info.Size()
+			}
+		}
+	}
+	return fs
+}
+
+// attachmentFile maps an attachment link in the note at notePath to a path
+func attachmentFile(rootPath, notePath, target string) string {
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if strings.Contains(target, "://") {
+		return ""
+	}
+	if rel, ok := strings.CutPrefix(target, "/attachments/"); ok {
+		return filepath.Join(rootPath, filepath.FromSlash(rel))
+	}
+	return filepath.Join(filepath.Dir(notePath), filepath.FromSlash(target))
+}
+
+// streaks finds the longest run of consecutive days and the run ending at
+// today (or the day before, as today may not have notes yet). days is oldest first.
+func streaks(days []DayStats, today string) (current, longest Streak) {
+	var run Streak
+	var prev time.Time
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		if run.Days > 0 && t.Equal(prev.AddDate(0, 0, 1)) {
+			run.End = d.Date
+			run.Days++
+		} else {
+			run = Streak{Start: d.Date, End: d.Date, Days: 1}
+		}
+		prev = t
+		if run.Days > longest.Days {
+			longest = run
+		}
+	}
+
+	if run.Days > 0 {
+		todayTime, err := time.Parse("2006-01-02", today)
+		if err == nil && (run.End == today || run.End == todayTime.AddDate(0, 0, -1).Format("2006-01-02")) {
+			current = run
+		}
+	}
+	return current, longest
+}
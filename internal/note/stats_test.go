@@ -0,0 +1,187 @@
+package note
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func days(dates ...string) []DayStats {
+	out := make([]DayStats, len(dates))
+	for i, d := range dates {
+		out[i] = DayStats{Date: d, Entries: 1}
+	}
+	return out
+}
+
+func TestStreaks(t *testing.T) {
+	tests := []struct {
+		name             string
+		days             []DayStats
+		today            string
+		current, longest Streak
+	}{
+		{
+			name:  "no notes",
+			today: "2025-06-30",
+		},
+		{
+			name:    "ends today",
+			days:    days("2025-06-28", "2025-06-29", "2025-06-30"),
+			today:   "2025-06-30",
+			current: Streak{"2025-06-28", "2025-06-30", 3},
+			longest: Streak{"2025-06-28", "2025-06-30", 3},
+		},
+		{
+			name:    "ends yesterday, today not written yet",
+			days:    days("2025-06-28", "2025-06-29"),
+			today:   "2025-06-30",
+			current: Streak{"2025-06-28", "2025-06-29", 2},
+			longest: Streak{"2025-06-28", "2025-06-29", 2},
+		},
+		{
+			name:    "ended the day before yesterday",
+			days:    days("2025-06-27", "2025-06-28"),
+			today:   "2025-06-30",
+			longest: Streak{"2025-06-27", "2025-06-28", 2},
+		},
+		{
+			name:    "gap splits runs, longest is the earlier one",
+			days:    days("2025-06-01", "2025-06-02", "2025-06-03", "2025-06-05", "2025-06-29", "2025-06-30"),
+			today:   "2025-06-30",
+			current: Streak{"2025-06-29", "2025-06-30", 2},
+			longest: Streak{"2025-06-01", "2025-06-03", 3},
+		},
+		{
+			name:    "first of equal runs is the longest",
+			days:    days("2025-06-01", "2025-06-02", "2025-06-10", "2025-06-11"),
+			today:   "2025-06-11",
+			current: Streak{"2025-06-10", "2025-06-11", 2},
+			longest: Streak{"2025-06-01", "2025-06-02", 2},
+		},
+		{
+			name:    "across a month and year end",
+			days:    days("2024-12-30", "2024-12-31", "2025-01-01"),
+			today:   "2025-01-02",
+			current: Streak{"2024-12-30", "2025-01-01", 3},
+			longest: Streak{"2024-12-30", "2025-01-01", 3},
+		},
+		{
+			name:    "unparsable dates are ignored",
+			days:    days("2025-06-29", "junk", "2025-06-30"),
+			today:   "2025-06-30",
+			current: Streak{"2025-06-29", "2025-06-30", 2},
+			longest: Streak{"2025-06-29", "2025-06-30", 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := streaks(tt.days, tt.today)
+			if current != tt.current {
+				t.Errorf("current = %+v, want %+v", current, tt.current)
+			}
+			if longest != tt.longest {
+				t.Errorf("longest = %+v, want %+v", longest, tt.longest)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCountFileAttachments(t *testing.T) {
+	root := t.TempDir()
+	day := time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local)
+	notePath := LayoutMonthly.DailyFile(root, day)
+	attDir := filepath.Join(LayoutMonthly.Dir(root, day), "Attachment")
+	writeFile(t, filepath.Join(attDir, "a.png"), 100)
+	writeFile(t, filepath.Join(attDir, "b c.pdf"), 250)
+	writeFile(t, filepath.Join(attDir, "d.jpg"), 1000)
+
+	tests := []struct {
+		name    string
+		content string
+		count   int
+		bytes   int64
+	}{
+		{"no attachments", "- [09:00] plain", 0, 0},
+		{"absolute link", "- [09:00] ![](/attachments/2025/06/Attachment/a.png)", 1, 100},
+		{"relative link", "- [09:00] ![](Attachment/a.png)", 1, 100},
+		{"escaped space", "- [09:00] [doc](Attachment/b%20c.pdf)", 1, 250},
+		{"query and fragment", "- [09:00] ![](Attachment/d.jpg?w=200) [doc](Attachment/b%20c.pdf#page=2)", 2, 1250},
+		{
+			"same file in both styles counts once",
+			"- [09:00] ![](Attachment/a.png)\n- [10:00] ![](/attachments/2025/06/Attachment/a.png)",
+			1, 100,
+		},
+		{"missing file counts without size", "- [09:00] ![](Attachment/gone.png)", 1, 0},
+		{"remote links are not attachments", "- [09:00] ![](https://example.com/Attachment/a.png)", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := countFile(root, notePath, "2025-06-30", tt.content)
+			if fs.day.Attachments != tt.count || fs.day.AttachmentBytes != tt.bytes {
+				t.Errorf("got %d attachments, %d bytes; want %d, %d",
+					fs.day.Attachments, fs.day.AttachmentBytes, tt.count, tt.bytes)
+			}
+		})
+	}
+}
+
+func TestCountFile(t *testing.T) {
+	content := "- [09:15] 早上好 #life\n- [09:45] review PR #work #life\n  second line\n- [21:00] done #work\n"
+	fs := countFile(t.TempDir(), "2025-06-30.md", "2025-06-30", content)
+
+	if fs.day.Entries != 3 || fs.day.Words != 12 {
+		t.Errorf("entries %d words %d, want 3 and 12", fs.day.Entries, fs.day.Words)
+	}
+	if fs.hours[9] != 2 || fs.hours[21] != 1 {
+		t.Errorf("hours = %v", fs.hours)
+	}
+	if fs.tags["life"] != 2 || fs.tags["work"] != 2 {
+		t.Errorf("tags = %v", fs.tags)
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	for _, d := range []time.Time{now.AddDate(0, 0, -3), now.AddDate(0, 0, -1), now} {
+		if _, err := SaveNoteAt(root, LayoutMonthly, "note #tag", d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewStatsCache(NewDateIndex(root, LayoutMonthly))
+	stats, err := cache.Stats("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ActiveDays != 3 || stats.Entries != 3 || stats.CurrentStreak.Days != 2 || stats.LongestStreak.Days != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.Start != now.AddDate(0, 0, -3).Format("2006-01-02") || stats.End != now.Format("2006-01-02") {
+		t.Errorf("range = %s – %s", stats.Start, stats.End)
+	}
+
+	// A changed file is counted again without invalidating
+	if _, err := SaveNoteAt(root, LayoutMonthly, "another #tag", now); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = cache.Stats(now.Format("2006-01-02"), now.Format("2006-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 || len(stats.Tags) != 1 || stats.Tags[0].Total != 2 {
+		t.Errorf("after append: %+v", stats)
+	}
+}
@@ -0,0 +1,23 @@
+package note
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tagRegex matches #tag words; "# Heading" is not a tag
+var tagRegex = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+
+// Tags returns the distinct #tags in content, lower-cased, in order of appearance
+func Tags(content string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, m := range tagRegex.FindAllStringSubmatch(content, -1) {
+		tag := strings.ToLower(m[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
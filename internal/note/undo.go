@@ -0,0 +1,85 @@
+package note
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+// ErrEntryChanged is returned by RemoveEntry when the daily file no longer
+// holds the entry where it was written
+var ErrEntryChanged = errors.New("note was edited or moved since it was saved")
+
+// SavedEntry records where SaveNote wrote a note
+type SavedEntry struct {
+	ID      string    `json:"id"`      // Unique per save
+	File    string    `json:"file"`    // Daily file the entry was appended to
+	Offset  int64     `json:"offset"`  // Byte offset of the entry in File
+	Text    string    `json:"-"`       // Exact bytes written, "- [HH:MM] ...\n"
+	Prefix  string    `json:"-"`       // "\n" written before Text when the file lacked a trailing newline
+	Content string    `json:"content"` // The note as typed
+	SavedAt time.Time `json:"savedAt"`
+	Queued  bool      `json:"queued"` // Storage was unavailable; the note waits in the capture queue and File is empty
+}
+
+// newEntryID returns a sortable unique ID such as 20250101-103000-1a2b3c4d
+func newEntryID(t time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// RemoveEntry deletes exactly the bytes SaveNote wrote for e. It refuses with
+// ErrEntryChanged if the file was edited around the entry (its bytes are no
+// longer at the recorded offset, or it no longer starts a line). Notes saved
+// later stay in place. The newline SaveNote added to end the previous line goes
+// too when nothing follows the entry, leaving the file as it was before.
+func RemoveEntry(e SavedEntry) error {
+	// Hold the lock so no note is appended between reading and replacing the file
+	f, err := fsutil.OpenLocked(e.File, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrEntryChanged
+		}
+		return fmt.Errorf("failed to open note file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read note file: %w", err)
+	}
+
+	end := e.Offset + int64(len(e.Text))
+	if e.Text == "" || end > int64(len(data)) || string(data[e.Offset:end]) != e.Text {
+		return ErrEntryChanged
+	}
+	if e.Offset > 0 && data[e.Offset-1] != '\n' {
+		return ErrEntryChanged
+	}
+
+	// Drop the newline SaveNote added before the entry, unless later notes
+	// now need it to start their line
+	start := e.Offset
+	tail := data[end:]
+	if e.Prefix != "" && len(tail) == 0 && start >= int64(len(e.Prefix)) &&
+		string(data[start-int64(len(e.Prefix)):start]) == e.Prefix {
+		start -= int64(len(e.Prefix))
+	}
+
+	// Rewrite in place rather than replacing the file: writers waiting on the
+	// lock keep a valid handle, and Windows can't rename over an open file
+	if _, err := f.WriteAt(tail, start); err != nil {
+		return fmt.Errorf("failed to write note file: %w", err)
+	}
+	if err := f.Truncate(start + int64(len(tail))); err != nil {
+		return fmt.Errorf("failed to truncate note file: %w", err)
+	}
+	return f.Sync()
+}
@@ -0,0 +1,100 @@
+package note
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRemoveEntry(t *testing.T) {
+	now := time.Date(2025, 6, 30, 9, 15, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		before  string   // File content before the first save, "" for no file
+		saves   []string // Notes saved in order; the first one is removed
+		edit    string   // Replaces the file before removing when set
+		want    string
+		changed bool
+	}{
+		{
+			name:  "only entry",
+			saves: []string{"first"},
+			want:  "",
+		},
+		{
+			name:   "after a trailing newline",
+			before: "- [08:00] by hand\n",
+			saves:  []string{"captured"},
+			want:   "- [08:00] by hand\n",
+		},
+		{
+			name:   "no trailing newline",
+			before: "- [08:00] by hand",
+			saves:  []string{"captured"},
+			want:   "- [08:00] by hand",
+		},
+		{
+			name:   "no trailing newline, later notes keep their line",
+			before: "- [08:00] by hand",
+			saves:  []string{"captured", "later"},
+			want:   "- [08:00] by hand\n- [09:15] later\n",
+		},
+		{
+			name:    "edited since",
+			saves:   []string{"captured"},
+			edit:    "- [09:15] captured, then edited\n",
+			want:    "- [09:15] captured, then edited\n",
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := LayoutMonthly.DailyFile(root, now)
+			if tt.before != "" {
+				if err := os.MkdirAll(LayoutMonthly.Dir(root, now), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(tt.before), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var first SavedEntry
+			for i, content := range tt.saves {
+				saved, err := SaveNoteAt(root, LayoutMonthly, content, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i == 0 {
+					first = saved
+				}
+			}
+			if tt.edit != "" {
+				if err := os.WriteFile(path, []byte(tt.edit), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := RemoveEntry(first)
+			if tt.changed {
+				if !errors.Is(err, ErrEntryChanged) {
+					t.Errorf("err = %v, want ErrEntryChanged", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
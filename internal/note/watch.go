@@ -0,0 +1,43 @@
+package note
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+// dailyFileRegex matches a daily file name and captures its date
+var dailyFileRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.md$`)
+
+// watchDebounce collapses the burst of events of one save
+const watchDebounce = 300 * time.Millisecond
+
+// WatchNotes calls onChange with the dates (YYYY-MM-DD, sorted) of daily
+// files created, edited or removed under rootPath, in any layout, until ctx
+// is done. Attachment, trash and other hidden folders are ignored.
+func WatchNotes(ctx context.Context, rootPath string, onChange func(dates []string)) error {
+	skipDir := func(name string) bool {
+		return name == "Attachment" || strings.HasPrefix(name, ".")
+	}
+	return fsutil.WatchTree(ctx, rootPath, watchDebounce, skipDir, func(paths []string) {
+		seen := map[string]bool{}
+		dates := []string{}
+		for _, path := range paths {
+			m := dailyFileRegex.FindStringSubmatch(filepath.Base(path))
+			if m == nil || seen[m[1]] {
+				continue
+			}
+			seen[m[1]] = true
+			dates = append(dates, m[1])
+		}
+		if len(dates) > 0 {
+			sort.Strings(dates)
+			onChange(dates)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package note
+
+import "unicode"
+
+// CountWords counts the words in s. Chinese and Japanese text has no spaces,
+// so every Han, Hiragana or Katakana character counts as a word on its own.
+func CountWords(s string) int {
+	count := 0
+	inWord := false
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			count++
+			inWord = false
+		case unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '\'' && r != '-'):
+			inWord = false
+		default:
+			if !inWord {
+				count++
+				inWord = true
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,27 @@
+package note
+
+import "testing"
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"   \n\t", 0},
+		{"hello world", 2},
+		{"don't stop well-known", 3},
+		{"one,two;three... four!", 4},
+		{"#tag and [x] done", 4},
+		{"今天天气很好", 6},
+		{"今天 meeting 很长。", 5},
+		{"カタカナとひらがな", 9},
+		{"读了 3 本书", 5},
+		{"emoji 🎉 counts", 3},
+	}
+	for _, tt := range tests {
+		if got := CountWords(tt.text); got != tt.want {
+			t.Errorf("CountWords(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
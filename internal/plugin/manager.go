@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"t-log/internal/command"
+)
+
+// DirName is the folder (next to config.json) plugins are loaded from
+const DirName = "plugins"
+
+// DataDirName is the folder (next to config.json) holding a working directory
+// for each plugin. Plugins don't run inside DirName: a file they write there
+// would reload every plugin, which would run them again.
+const DataDirName = "plugin-data"
+
+// IDPrefix starts the registry ID of every plugin command: plugin:{name}:{id}
+const IDPrefix = "plugin:"
+
+// Manager discovers plugins and dispatches requests to them
+type Manager struct {
+	dir     string
+	dataDir string
+
+	mu      sync.RWMutex
+	plugins []*Plugin
+}
+
+// NewManager creates a manager for the plugins in dir, each running in its own
+// folder under dataDir. Call Load to discover them.
+func NewManager(dir, dataDir string) *Manager {
+	return &Manager{dir: dir, dataDir: dataDir}
+}
+
+// Dir returns the plugins directory
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Watches reports whether a change to path can change the loaded plugins:
+// it is (or was) an executable in the plugins directory
+func (m *Manager) Watches(path string) bool {
+	for _, p := range m.Plugins() {
+		if p.Path == path {
+			return true
+		}
+	}
+	info, err := os.Stat(path)
+	return err == nil && isExecutable(path, info)
+}
+
+// Load (re)discovers the plugins and asks each one to describe itself.
+// A plugin that fails to describe itself is skipped; the others still load.
+// Reloading also re-enables plugins that were disabled after failures.
+func (m *Manager) Load() error {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var candidates []string
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(m.dir, e.Name())
+		if isExecutable(path, info) {
+			candidates = append(candidates, path)
+		}
+	}
+
+	// Describe in parallel so one slow plugin doesn't hold up the others
+	loaded := make([]*Plugin, len(candidates))
+	var wg sync.WaitGroup
+	for i, path := range candidates {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			p := &Plugin{Path: path, workDir: m.workDir(path)}
+			desc, err := describe(p)
+			if err != nil {
+				fmt.Printf("Error loading plugin %s: %v\n", filepath.Base(path), err)
+				return
+			}
+			p.Desc = *desc
+			loaded[i] = p
+		}(i, path)
+	}
+	wg.Wait()
+
+	plugins := []*Plugin{}
+	seen := map[string]bool{}
+	for _, p := range loaded {
+		if p == nil {
+			continue
+		}
+		if seen[p.Name()] {
+			fmt.Printf("Skipping plugin %s: another plugin is named %s\n", p.Path, p.Name())
+			continue
+		}
+		seen[p.Name()] = true
+		plugins = append(plugins, p)
+	}
+	// Hooks run in a predictable order: by name
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name() < plugins[j].Name() })
+
+	m.mu.Lock()
+	m.plugins = plugins
+	m.mu.Unlock()
+	return nil
+}
+
+// workDir returns the folder the plugin at path runs in, named after its file
+func (m *Manager) workDir(path string) string {
+	name := filepath.Base(path)
+	return filepath.Join(m.dataDir, strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+func describe(p *Plugin) (*Description, error) {
+	out, err := run(p.Path, p.workDir, Request{Type: RequestDescribe}, DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Description
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("invalid describe response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	if strings.ContainsAny(resp.Name, ": ") {
+		return nil, fmt.Errorf("invalid plugin name %q", resp.Name)
+	}
+	return &resp.Description, nil
+}
+
+// Plugins returns the loaded plugins
+func (m *Manager) Plugins() []*Plugin {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*Plugin(nil), m.plugins...)
+}
+
+// Commands returns the commands of every plugin, with IDs rewritten to
+// plugin:{name}:{id} so they cannot clash with built-in or user commands
+func (m *Manager) Commands() []command.Command {
+	var cmds []command.Command
+	for _, p := range m.Plugins() {
+		for _, c := range p.Desc.Commands {
+			c.ID = IDPrefix + p.Name() + ":" + c.ID
+			if c.Description == "" {
+				c.Description = "Plugin: " + p.Name()
+			}
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds
+}
+
+// RunCommand runs a command returned by Commands
+func (m *Manager) RunCommand(id string, args command.Args) (*Response, error) {
+	name, cmdID, ok := strings.Cut(strings.TrimPrefix(id, IDPrefix), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid plugin command id: %s", id)
+	}
+	for _, p := range m.Plugins() {
+		if p.Name() == name {
+			return p.Call(Request{Type: RequestCommand, Command: cmdID, Args: args})
+		}
+	}
+	return nil, fmt.Errorf("plugin %s is not loaded", name)
+}
+
+// PreSave passes the note through every plugin with the pre-save hook, in
+// name order. A failing plugin is logged and skipped so the note is never
+// lost. skip is true when a plugin asked for the note not to be saved.
+func (m *Manager) PreSave(n Note) (content string, skip bool) {
+	for _, p := range m.Plugins() {
+		if !p.HasHook(HookPreSave) || p.Disabled() {
+			continue
+		}
+		resp, err := p.Call(Request{Type: RequestPreSave, Note: &n})
+		if err != nil {
+			fmt.Printf("Error in pre-save hook: %v\n", err)
+			continue
+		}
+		if resp.Skip {
+			return n.Content, true
+		}
+		if resp.Content != "" {
+			n.Content = resp.Content
+		}
+	}
+	return n.Content, false
+}
+
+// PostSave notifies plugins with the post-save hook in the background.
+// Errors are only logged: the note is already on disk.
+func (m *Manager) PostSave(n Note) {
+	for _, p := range m.Plugins() {
+		if !p.HasHook(HookPostSave) || p.Disabled() {
+			continue
+		}
+		go func(p *Plugin) {
+			if _, err := p.Call(Request{Type: RequestPostSave, Note: &n}); err != nil {
+				fmt.Printf("Error in post-save hook: %v\n", err)
+			}
+		}(p)
+	}
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writePlugin installs a shell script plugin in dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts in these tests")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPluginsRunInTheirDataDir(t *testing.T) {
+	root := t.TempDir()
+	dir, dataDir := filepath.Join(root, DirName), filepath.Join(root, DataDirName)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Writes a cache file on every call, as many plugins do
+	writePlugin(t, dir, "cache.sh", `cat >/dev/null; date > cache.txt; echo '{"name":"cache"}'`)
+
+	m := NewManager(dir, dataDir)
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(m.Plugins()); got != 1 {
+		t.Fatalf("loaded %d plugins, want 1", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "cache", "cache.txt")); err != nil {
+		t.Errorf("plugin did not write to its data dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cache.txt")); err == nil {
+		t.Errorf("plugin wrote into the plugins directory")
+	}
+}
+
+func TestManagerWatches(t *testing.T) {
+	dir := t.TempDir()
+	loaded := writePlugin(t, dir, "hello", `cat >/dev/null; echo '{"name":"hello"}'`)
+
+	m := NewManager(dir, t.TempDir())
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := filepath.Join(dir, "notes.json")
+	if err := os.WriteFile(data, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := writePlugin(t, dir, "added", "")
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"loaded plugin", loaded, true},
+		{"new executable", added, true},
+		{"data file", data, false},
+		{"removed unknown file", filepath.Join(dir, "gone.txt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Watches(tt.path); got != tt.want {
+				t.Errorf("Watches(%s) = %v, want %v", filepath.Base(tt.path), got, tt.want)
+			}
+		})
+	}
+
+	// Removing a loaded plugin still has to reload
+	if err := os.Remove(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Watches(loaded) {
+		t.Errorf("removed plugin is not watched")
+	}
+}
+
+func TestPluginTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeoutMs int
+		typ       string
+		want      time.Duration
+	}{
+		{"default", 0, RequestCommand, DefaultTimeout},
+		{"own limit", 20000, RequestCommand, 20 * time.Second},
+		{"capped", 3600000, RequestCommand, MaxTimeout},
+		{"pre-save capped", 20000, RequestPreSave, MaxPreSaveTimeout},
+		{"pre-save shorter", 500, RequestPreSave, 500 * time.Millisecond},
+		{"post-save runs in the background", 20000, RequestPostSave, 20 * time.Second},
+	}
+	for _, tt := range tests {
+		p := &Plugin{Desc: Description{TimeoutMs: tt.timeoutMs}}
+		if got := p.timeout(tt.typ); got != tt.want {
+			t.Errorf("%s: timeout = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
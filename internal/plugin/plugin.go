@@ -0,0 +1,190 @@
+// Package plugin runs external executables that extend t-log. A plugin is any
+// executable in the plugins directory; it is started once per request, reads
+// one JSON Request from stdin and writes one JSON Response to stdout.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds every request unless the plugin asks for another limit
+	DefaultTimeout = 5 * time.Second
+
+	// maxFailures consecutive errors disable a plugin until plugins are reloaded,
+	// so a broken integration cannot slow down every save
+	maxFailures = 3
+
+	// maxOutput caps how much of a plugin's stdout is read
+	maxOutput = 1 << 20
+
+	// MaxTimeout bounds the timeout a plugin may ask for
+	MaxTimeout = time.Minute
+
+	// MaxPreSaveTimeout bounds pre-save hooks, which the capture waits for
+	MaxPreSaveTimeout = DefaultTimeout
+)
+
+// Plugin is one executable in the plugins directory
+type Plugin struct {
+	Path string
+	Desc Description
+
+	workDir string
+
+	mu       sync.Mutex
+	failures int
+}
+
+// Name returns the name the plugin reported, or its file name
+func (p *Plugin) Name() string {
+	if p.Desc.Name != "" {
+		return p.Desc.Name
+	}
+	return strings.TrimSuffix(filepath.Base(p.Path), filepath.Ext(p.Path))
+}
+
+// HasHook reports whether the plugin subscribed to a save hook
+func (p *Plugin) HasHook(hook string) bool {
+	for _, h := range p.Desc.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Disabled reports whether the plugin failed too often in a row
+func (p *Plugin) Disabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.failures >= maxFailures
+}
+
+// timeout is the limit for a request of type typ: the plugin's own, capped
+func (p *Plugin) timeout(typ string) time.Duration {
+	timeout := DefaultTimeout
+	if p.Desc.TimeoutMs > 0 {
+		timeout = time.Duration(p.Desc.TimeoutMs) * time.Millisecond
+	}
+	limit := MaxTimeout
+	if typ == RequestPreSave {
+		limit = MaxPreSaveTimeout
+	}
+	return min(timeout, limit)
+}
+
+// Call runs the plugin with req and decodes its response. A crash, a timeout,
+// output that is not JSON or a response with Error set are all errors.
+func (p *Plugin) Call(req Request) (*Response, error) {
+	if p.Disabled() {
+		return nil, fmt.Errorf("plugin %s is disabled after %d failures", p.Name(), maxFailures)
+	}
+
+	resp, err := call(p.Path, p.workDir, req, p.timeout(req.Type))
+
+	p.mu.Lock()
+	if err != nil {
+		p.failures++
+	} else {
+		p.failures = 0
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+	}
+	return resp, nil
+}
+
+// call runs the plugin once and decodes its Response
+func call(path, dir string, req Request, timeout time.Duration) (*Response, error) {
+	out, err := run(path, dir, req, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// run starts the executable in dir, writes req to stdin and returns its stdout
+func run(path, dir string, req Request, timeout time.Duration) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &limitedWriter{w: &stdout, n: maxOutput}
+	cmd.Stderr = &limitedWriter{w: &stderr, n: maxOutput}
+	// Don't wait forever on pipes held open by a grandchild after a kill
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedWriter discards everything after n bytes instead of failing the write,
+// so a chatty plugin is cut short rather than blocked
+type limitedWriter struct {
+	w *bytes.Buffer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if remaining := l.n - l.w.Len(); remaining > 0 {
+		if len(p) > remaining {
+			l.w.Write(p[:remaining])
+		} else {
+			l.w.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// isExecutable decides whether a directory entry is a plugin. On Windows that
+// is decided by extension, elsewhere by the executable bit.
+func isExecutable(path string, info os.FileInfo) bool {
+	if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd", ".com":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
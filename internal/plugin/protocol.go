@@ -0,0 +1,54 @@
+package plugin
+
+import "t-log/internal/command"
+
+// Request types sent to a plugin on stdin
+const (
+	RequestDescribe = "describe"  // What the plugin provides; sent when plugins are loaded
+	RequestCommand  = "command"   // Run one of the plugin's commands
+	RequestPreSave  = "pre-save"  // Transform a note before it is written
+	RequestPostSave = "post-save" // Notification after a note was written
+)
+
+// Hooks a plugin can subscribe to in its describe response
+const (
+	HookPreSave  = "pre-save"
+	HookPostSave = "post-save"
+)
+
+// Request is the single JSON document written to the plugin's stdin
+type Request struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command,omitempty"` // RequestCommand: the command id (without prefix)
+	Args    map[string]string `json:"args,omitempty"`    // RequestCommand: bound arguments
+	Note    *Note             `json:"note,omitempty"`    // RequestPreSave / RequestPostSave
+}
+
+// Note is what save hooks see
+type Note struct {
+	Content  string `json:"content"`
+	Notebook string `json:"notebook"`
+	Date     string `json:"date"`           // YYYY-MM-DD
+	Time     string `json:"time"`           // HH:MM
+	File     string `json:"file,omitempty"` // Daily file the note was written to (post-save only)
+}
+
+// Description is a plugin's answer to RequestDescribe
+type Description struct {
+	Name      string            `json:"name"`
+	Commands  []command.Command `json:"commands"`
+	Hooks     []string          `json:"hooks"`
+	TimeoutMs int               `json:"timeout_ms"` // Per-request limit; 0 uses DefaultTimeout, capped at MaxTimeout (MaxPreSaveTimeout for pre-save)
+}
+
+// Response is the single JSON document a plugin writes to stdout.
+// Every field is optional.
+type Response struct {
+	Error   string `json:"error,omitempty"`    // Reported as the request's error
+	Content string `json:"content,omitempty"`  // RequestPreSave: replacement note content
+	Skip    bool   `json:"skip,omitempty"`     // RequestPreSave: drop the note (e.g. it was routed elsewhere)
+	Insert  string `json:"insert,omitempty"`   // RequestCommand: text to insert at the cursor
+	Append  string `json:"append,omitempty"`   // RequestCommand: text to save as a new note
+	OpenURL string `json:"open_url,omitempty"` // RequestCommand: URL to open in the browser
+	Message string `json:"message,omitempty"`  // RequestCommand: shown to the user
+}
@@ -0,0 +1,189 @@
+// Package queue keeps captured notes on local disk until their notebook's
+// storage (a network share, a removable disk) can be written again
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"t-log/internal/fsutil"
+)
+
+// DirName is the queue's folder inside the config directory
+const DirName = "queue"
+
+// Item is a captured note waiting to be written to its notebook
+type Item struct {
+	ID        string    `json:"id"`
+	Notebook  string    `json:"notebook"`
+	RootPath  string    `json:"root_path"` // Where the note goes, as configured when it was captured
+	Layout    string    `json:"layout"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"` // Capture time; the note is filed under this date and time
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Queue stores one JSON file per item, each written atomically and synced,
+// so an accepted capture survives a crash or power loss. Delivery is at
+// least once: a crash between writing the note and removing its file
+// delivers it again.
+type Queue struct {
+	dir      string
+	mu       sync.Mutex // Guards the item files
+	flushing sync.Mutex // One Flush at a time
+}
+
+// Open returns the queue stored in dir, creating the folder if needed
+func Open(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	return &Queue{dir: dir}, nil
+}
+
+// Add stores item and returns it with its ID set
+func (q *Queue) Add(item Item) (Item, error) {
+	b := make([]byte, 4)
+	rand.Read(b)
+	// Sortable by name, so files list in capture order
+	item.ID = item.CreatedAt.UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(b)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.write(item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// Items returns the pending items, oldest first. Unreadable files are skipped.
+func (q *Queue) Items() ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items()
+}
+
+// Len returns the number of pending items
+func (q *Queue) Len() int {
+	items, err := q.Items()
+	if err != nil {
+		return 0
+	}
+	return len(items)
+}
+
+// HasPending reports whether notes for rootPath are waiting. New captures
+// for it must queue behind them to keep their order.
+func (q *Queue) HasPending(rootPath string) bool {
+	items, err := q.Items()
+	if err != nil {
+		return false
+	}
+	for _, item := range items {
+		if item.RootPath == rootPath {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush passes each pending item to deliver, oldest first, and removes the
+// ones delivered. After a failure the remaining items for the same root are
+// left for the next Flush so notes keep their order. Returns how many were
+// delivered and the last delivery error.
+func (q *Queue) Flush(deliver func(Item) error) (int, error) {
+	q.flushing.Lock()
+	defer q.flushing.Unlock()
+
+	items, err := q.Items()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var lastErr error
+	blocked := map[string]bool{}
+	for _, item := range items {
+		if blocked[item.RootPath] {
+			continue
+		}
+
+		if err := deliver(item); err != nil {
+			blocked[item.RootPath] = true
+			lastErr = err
+			item.Attempts++
+			item.LastError = err.Error()
+			q.mu.Lock()
+			if werr := q.write(item); werr != nil {
+				fmt.Printf("Error updating queued note: %v\n", werr)
+			}
+			q.mu.Unlock()
+			continue
+		}
+
+		q.mu.Lock()
+		err := os.Remove(q.path(item.ID))
+		q.mu.Unlock()
+		if err != nil && !os.IsNotExist(err) {
+			// Delivered but would be delivered again; stop rather than duplicate more
+			return delivered, fmt.Errorf("failed to remove delivered note: %w", err)
+		}
+		delivered++
+	}
+	return delivered, lastErr
+}
+
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
+
+func (q *Queue) write(item Item) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queued note: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(q.path(item.ID), data, 0600); err != nil {
+		return fmt.Errorf("failed to queue note: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) items() ([]Item, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	var items []Item
+	for _, e := range entries {
+		// Skips WriteFileAtomic's temp files too
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.dir, e.Name()))
+		if err != nil {
+			fmt.Printf("Error reading queued note %s: %v\n", e.Name(), err)
+			continue
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			fmt.Printf("Error reading queued note %s: %v\n", e.Name(), err)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
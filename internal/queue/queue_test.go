@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFlushDeliversInOrder(t *testing.T) {
+	q, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2025, 6, 30, 9, 15, 0, 0, time.Local)
+	add := func(root, content string, offset time.Duration) {
+		t.Helper()
+		if _, err := q.Add(Item{RootPath: root, Content: content, CreatedAt: base.Add(offset)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Added out of order: capture time decides
+	add("/share", "second", time.Minute)
+	add("/share", "first", 0)
+	add("/local", "other", 30*time.Second)
+	add("/share", "third", 2*time.Minute)
+
+	// The share is still offline: nothing for it is tried after its first failure
+	offline := errors.New("share offline")
+	var tried []string
+	delivered, err := q.Flush(func(item Item) error {
+		tried = append(tried, item.Content)
+		if item.RootPath == "/share" {
+			return offline
+		}
+		return nil
+	})
+	if !errors.Is(err, offline) {
+		t.Errorf("Flush error = %v, want %v", err, offline)
+	}
+	if delivered != 1 {
+		t.Errorf("delivered %d, want 1", delivered)
+	}
+	if want := []string{"first", "other"}; !slices.Equal(tried, want) {
+		t.Errorf("tried %v, want %v", tried, want)
+	}
+
+	items, err := q.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].Attempts != 1 || items[0].LastError != offline.Error() {
+		t.Fatalf("pending after failure = %+v", items)
+	}
+	if !q.HasPending("/share") || q.HasPending("/local") {
+		t.Errorf("HasPending is wrong after a partial flush")
+	}
+
+	// Back online: the rest go in order, under the time they were captured
+	var got []Item
+	delivered, err = q.Flush(func(item Item) error {
+		got = append(got, item)
+		return nil
+	})
+	if err != nil || delivered != 3 {
+		t.Fatalf("Flush = %d, %v", delivered, err)
+	}
+	want := []struct {
+		content string
+		at      time.Time
+	}{
+		{"first", base},
+		{"second", base.Add(time.Minute)},
+		{"third", base.Add(2 * time.Minute)},
+	}
+	for i, w := range want {
+		if got[i].Content != w.content || !got[i].CreatedAt.Equal(w.at) {
+			t.Errorf("delivery %d = %q at %v, want %q at %v", i, got[i].Content, got[i].CreatedAt, w.content, w.at)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("%d notes left after delivery", q.Len())
+	}
+}
+
+func TestQueueSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 6, 30, 23, 59, 30, 0, time.UTC)
+	added, err := q.Add(Item{Notebook: "work", RootPath: "/share", Layout: "flat", Content: "late thought", CreatedAt: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := reopened.Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	item := items[0]
+	if item.ID != added.ID || item.Content != "late thought" || item.Layout != "flat" || item.Notebook != "work" {
+		t.Errorf("reopened item = %+v", item)
+	}
+	if !item.CreatedAt.Equal(at) {
+		t.Errorf("CreatedAt = %v, want %v", item.CreatedAt, at)
+	}
+}
@@ -0,0 +1,376 @@
+// Package report turns a week or month of notes into a status report
+// rendered from a user-editable text/template
+package report
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"t-log/internal/attachment"
+	"t-log/internal/fsutil"
+	"t-log/internal/note"
+	"t-log/internal/usertmpl"
+)
+
+// TemplateDirName is the folder in the config dir holding edited templates
+const TemplateDirName = "report-templates"
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Period is the span a report covers
+type Period string
+
+const (
+	Weekly  Period = "week"  // Monday to Sunday
+	Monthly Period = "month" // 1st to the last day of the month
+)
+
+// ParsePeriod accepts "week"/"weekly" and "month"/"monthly"
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(s) {
+	case "", "week", "weekly":
+		return Weekly, nil
+	case "month", "monthly":
+		return Monthly, nil
+	}
+	return "", fmt.Errorf("unknown report period %q (use week or month)", s)
+}
+
+// Span returns the first and last day of the period containing t
+func (p Period) Span(t time.Time) (time.Time, time.Time) {
+	if p == Monthly {
+		start := note.StartOfMonth(t)
+		return start, start.AddDate(0, 1, -1)
+	}
+	start := note.StartOfWeek(t)
+	return start, start.AddDate(0, 0, 6)
+}
+
+// Label names the period starting at start: 2025-W03 or 2025-01
+func (p Period) Label(start time.Time) string {
+	if p == Monthly {
+		return start.Format("2006-01")
+	}
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// File is where the report for the period starting at start is stored:
+// RootPath/YYYY/Wnn-report.md or RootPath/YYYY/MM-report.md, whatever the layout
+func (p Period) File(rootPath string, start time.Time) string {
+	if p == Monthly {
+		return filepath.Join(rootPath, start.Format("2006"), start.Format("01")+"-report.md")
+	}
+	year, week := start.ISOWeek()
+	return filepath.Join(rootPath, fmt.Sprint(year), fmt.Sprintf("W%02d-report.md", week))
+}
+
+// TemplateName is the template file used for p
+func (p Period) TemplateName() string {
+	if p == Monthly {
+		return "monthly.tmpl"
+	}
+	return "weekly.tmpl"
+}
+
+// Entry is one note in the report
+type Entry struct {
+	Time    string
+	Content string
+	Tags    []string
+	Words   int
+}
+
+// Day holds the notes of one day
+type Day struct {
+	Date    string // YYYY-MM-DD
+	Weekday string // Monday...
+	Entries []Entry
+}
+
+// Task is a "[ ]" or "[x]" item found in a note
+type Task struct {
+	Date string
+	Time string
+	Text string
+	Done bool
+}
+
+// TagCount is how often a #tag was used in the period
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Attachment is a file linked from the period's notes
+type Attachment struct {
+	Name    string
+	Date    string // Day of the note linking it
+	Link    string // Relative to the report file, for markdown links
+	Size    int64  // 0 when the file is missing
+	IsImage bool
+	Missing bool
+}
+
+// Counts are the totals templates usually print in a summary line
+type Counts struct {
+	Entries         int
+	Days            int // Days with at least one note
+	Words           int
+	Tasks           int
+	Done            int
+	Open            int
+	Tags            int // Distinct tags
+	Attachments     int
+	AttachmentBytes int64
+}
+
+// Data is what report templates receive
+type Data struct {
+	Period      Period
+	Label       string // 2025-W03 or 2025-01
+	Notebook    string
+	Start       string // YYYY-MM-DD
+	End         string
+	Generated   time.Time
+	Days        []Day
+	Tags        []TagCount // Most used first
+	Done        []Task
+	Open        []Task
+	Attachments []Attachment
+	Counts      Counts
+}
+
+// Options locates the notebook and the edited templates
+type Options struct {
+	Notebook    string
+	RootPath    string
+	Layout      note.Layout
+	TemplateDir string // Optional folder whose weekly.tmpl/monthly.tmpl replace the built-in ones
+}
+
+// taskRegex matches markdown task items, with or without a list marker
+// (the entry's own "- [HH:MM]" is already stripped from its first line)
+var taskRegex = regexp.MustCompile(`(?m)^\s*(?:[-*+]\s+)?\[([ xX])\]\s+(.+)$`)
+
+// Collect gathers the data for the period containing t
+func Collect(p Period, t time.Time, opts Options) (Data, error) {
+	start, end := p.Span(t)
+	data := Data{
+		Period:      p,
+		Label:       p.Label(start),
+		Notebook:    opts.Notebook,
+		Start:       start.Format("2006-01-02"),
+		End:         end.Format("2006-01-02"),
+		Generated:   time.Now(),
+		Days:        []Day{},
+		Tags:        []TagCount{},
+		Done:        []Task{},
+		Open:        []Task{},
+		Attachments: []Attachment{},
+	}
+
+	entries, err := note.ReadEntries(opts.RootPath, opts.Layout, data.Start, data.End)
+	if err != nil {
+		return Data{}, err
+	}
+
+	reportDir := filepath.Dir(p.File(opts.RootPath, start))
+	tagCounts := map[string]int{}
+	seenFiles := map[string]bool{}
+
+	for _, ne := range entries {
+		if len(data.Days) == 0 || data.Days[len(data.Days)-1].Date != ne.Date {
+			data.Days = append(data.Days, Day{Date: ne.Date, Weekday: weekday(ne.Date)})
+		}
+		e := Entry{Time: ne.Timestamp, Content: ne.Content, Tags: note.Tags(ne.Content), Words: note.CountWords(ne.Content)}
+		day := &data.Days[len(data.Days)-1]
+		day.Entries = append(day.Entries, e)
+
+		data.Counts.Entries++
+		data.Counts.Words += e.Words
+		for _, tag := range e.Tags {
+			tagCounts[tag]++
+		}
+
+		for _, m := range taskRegex.FindAllStringSubmatch(ne.Content, -1) {
+			task := Task{Date: ne.Date, Time: ne.Timestamp, Text: strings.TrimSpace(m[2]), Done: m[1] != " "}
+			if task.Done {
+				data.Done = append(data.Done, task)
+			} else {
+				data.Open = append(data.Open, task)
+			}
+		}
+
+		day0, _ := time.ParseInLocation("2006-01-02", ne.Date, time.Local)
+		notePath := opts.Layout.DailyFile(opts.RootPath, day0)
+		for _, path := range attachment.References(opts.RootPath, notePath, ne.Content) {
+			if seenFiles[path] {
+				continue
+			}
+			seenFiles[path] = true
+			data.Attachments = append(data.Attachments, newAttachment(path, ne.Date, reportDir))
+		}
+	}
+
+	for tag, n := range tagCounts {
+		data.Tags = append(data.Tags, TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(data.Tags, func(i, j int) bool {
+		if data.Tags[i].Count != data.Tags[j].Count {
+			return data.Tags[i].Count > data.Tags[j].Count
+		}
+		return data.Tags[i].Tag < data.Tags[j].Tag
+	})
+
+	data.Counts.Days = len(data.Days)
+	data.Counts.Done = len(data.Done)
+	data.Counts.Open = len(data.Open)
+	data.Counts.Tasks = data.Counts.Done + data.Counts.Open
+	data.Counts.Tags = len(data.Tags)
+	data.Counts.Attachments = len(data.Attachments)
+	for _, a := range data.Attachments {
+		data.Counts.AttachmentBytes += a.Size
+	}
+	return data, nil
+}
+
+func newAttachment(path, date, reportDir string) Attachment {
+	a := Attachment{Name: filepath.Base(path), Date: date}
+	if rel, err := filepath.Rel(reportDir, path); err == nil {
+		a.Link = filepath.ToSlash(rel)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		a.Missing = true
+		return a
+	}
+	a.Size = info.Size()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".bmp", ".svg":
+		a.IsImage = true
+	}
+	return a
+}
+
+func weekday(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return t.Weekday().String()
+}
+
+// templateFuncs are available to report templates
+var templateFuncs = template.FuncMap{
+	"indent": usertmpl.Indent,
+	"join":   strings.Join,
+	"size":   attachment.FormatSize,
+	// firstLine shortens a note to its first line
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(s, "\n")
+		return line
+	},
+}
+
+// DefaultTemplate returns the built-in template for p, e.g. to seed a copy
+// the user can edit
+func DefaultTemplate(p Period) []byte {
+	data, err := builtinTemplates.ReadFile("templates/" + p.TemplateName())
+	if err != nil {
+		panic(err) // Embedded at build time
+	}
+	return data
+}
+
+// Render executes the template for data.Period: the edited one in
+// templateDir when present, the built-in one otherwise
+func Render(data Data, templateDir string) ([]byte, error) {
+	name := data.Period.TemplateName()
+	src, err := usertmpl.Source(builtinTemplates, templateDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Generate writes the report for the period containing t next to the notes
+// and returns its path. An existing report for the period, which may have
+// been edited since, is kept as <name>.bak (<name>.2.bak... when that is taken).
+func Generate(p Period, t time.Time, opts Options) (string, error) {
+	data, err := Collect(p, t, opts)
+	if err != nil {
+		return "", err
+	}
+	content, err := Render(data, opts.TemplateDir)
+	if err != nil {
+		return "", err
+	}
+
+	start, _ := p.Span(t)
+	path := p.File(opts.RootPath, start)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := backup(path, content); err != nil {
+		return "", err
+	}
+	if err := fsutil.WriteFileAtomic(path, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
+
+// maxBackups bounds how many numbered backups of one report are tried
+const maxBackups = 1000
+
+// backupName is the n-th backup of the report at path:
+// W27-report.md.bak, W27-report.md.2.bak, W27-report.md.3.bak...
+func backupName(path string, n int) string {
+	if n == 1 {
+		return path + ".bak"
+	}
+	return fmt.Sprintf("%s.%d.bak", path, n)
+}
+
+// backup moves the report at path to the first free backupName unless there
+// is none or it already holds content. Earlier backups are never replaced:
+// one of them may hold edits made before a previous regeneration.
+func backup(path string, content []byte) error {
+	old, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && bytes.Equal(old, content)) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read existing report: %w", err)
+	}
+	for n := 1; n <= maxBackups; n++ {
+		name := backupName(path, n)
+		if _, err := os.Lstat(name); !os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(path, name); err != nil {
+			return fmt.Errorf("failed to back up existing report: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to back up existing report: too many backups of %s", filepath.Base(path))
+}
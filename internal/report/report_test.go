@@ -0,0 +1,153 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // America/New_York must load without a zoneinfo database
+
+	"t-log/internal/note"
+)
+
+func TestGenerateKeepsPreviousReport(t *testing.T) {
+	root := t.TempDir()
+	day := time.Date(2025, 6, 30, 12, 0, 0, 0, time.Local)
+	if _, err := note.SaveNoteAt(root, note.LayoutMonthly, "- [x] shipped #work", day); err != nil {
+		t.Fatal(err)
+	}
+
+	tmplDir := t.TempDir()
+	writeTemplate := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmplDir, Weekly.TemplateName()), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTemplate("{{.Label}}: {{.Counts.Done}} done\n")
+	opts := Options{RootPath: root, Layout: note.LayoutMonthly, TemplateDir: tmplDir}
+
+	path, err := Generate(Weekly, day, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "2025", "W27-report.md"); path != want {
+		t.Fatalf("path = %s, want %s", path, want)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("first report left a backup")
+	}
+
+	// Regenerating the same report changes nothing
+	if _, err := Generate(Weekly, day, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("unchanged report was backed up")
+	}
+
+	// The user edits the report, then regenerates it
+	if err := os.WriteFile(path, []byte("edited by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(Weekly, day, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path + ".bak"); string(got) != "edited by hand\n" {
+		t.Errorf("backup = %q", got)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "2025-W27: 1 done\n" {
+		t.Errorf("report = %q", got)
+	}
+
+	// Regenerating again after more notes keeps the edited copy
+	if _, err := note.SaveNoteAt(root, note.LayoutMonthly, "- [x] reviewed", day); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(Weekly, day, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path + ".bak"); string(got) != "edited by hand\n" {
+		t.Errorf("edited backup replaced: %q", got)
+	}
+	if got, _ := os.ReadFile(path + ".2.bak"); string(got) != "2025-W27: 1 done\n" {
+		t.Errorf("second backup = %q", got)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "2025-W27: 2 done\n" {
+		t.Errorf("report = %q", got)
+	}
+}
+
+func TestRenderTemplates(t *testing.T) {
+	data := Data{Period: Monthly, Label: "2025-06", Days: []Day{{Date: "2025-06-30", Weekday: "Monday", Entries: []Entry{{Time: "09:00", Content: "two\nlines"}}}}}
+
+	// Built-in templates parse and run
+	for _, p := range []Period{Weekly, Monthly} {
+		data.Period = p
+		if _, err := Render(data, ""); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+	}
+
+	dir := t.TempDir()
+	src := "{{range .Days}}{{range .Entries}}- {{indent .Content}}{{end}}{{end}}\n"
+	if err := os.WriteFile(filepath.Join(dir, Monthly.TemplateName()), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data.Period = Monthly
+	got, err := Render(data, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- two\n  lines\n"; string(got) != want {
+		t.Errorf("edited template: got %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, Monthly.TemplateName()), []byte("{{.Nope"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Render(data, dir); err == nil || !strings.Contains(err.Error(), "invalid report template") {
+		t.Errorf("broken template: err = %v", err)
+	}
+}
+
+func TestPeriodSpan(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		period     Period
+		t          time.Time
+		start, end string
+		label      string
+	}{
+		{"week on a late sunday", Weekly, time.Date(2025, 6, 29, 23, 59, 0, 0, loc), "2025-06-23", "2025-06-29", "2025-W26"},
+		{"week on monday midnight", Weekly, time.Date(2025, 6, 30, 0, 0, 0, 0, loc), "2025-06-30", "2025-07-06", "2025-W27"},
+		{"week of a local sunday that is monday in UTC", Weekly, time.Date(2025, 6, 30, 3, 0, 0, 0, time.UTC).In(loc), "2025-06-23", "2025-06-29", "2025-W26"},
+		{"ISO week in the previous year", Weekly, time.Date(2027, 1, 3, 23, 0, 0, 0, loc), "2026-12-28", "2027-01-03", "2026-W53"},
+		{"month on its last evening", Monthly, time.Date(2025, 2, 28, 23, 59, 0, 0, loc), "2025-02-01", "2025-02-28", "2025-02"},
+		{"month of a local day that is the 1st in UTC", Monthly, time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC).In(loc), "2025-02-01", "2025-02-28", "2025-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.period.Span(tt.t)
+			if got := start.Format("2006-01-02"); got != tt.start {
+				t.Errorf("start = %s, want %s", got, tt.start)
+			}
+			if got := end.Format("2006-01-02"); got != tt.end {
+				t.Errorf("end = %s, want %s", got, tt.end)
+			}
+			if start.Location() != loc || end.Location() != loc {
+				t.Errorf("span left %s: %v - %v", loc, start, end)
+			}
+			if got := tt.period.Label(start); got != tt.label {
+				t.Errorf("label = %s, want %s", got, tt.label)
+			}
+		})
+	}
+}
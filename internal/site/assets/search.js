@@ -0,0 +1,53 @@
+// Client-side search over window.SEARCH_INDEX (written by search-index.js).
+// Every word must appear in a note; #tag matches the tag.
+(function () {
+  const input = document.getElementById('search-input');
+  const status = document.getElementById('search-status');
+  const list = document.getElementById('search-results');
+  const index = (window.SEARCH_INDEX || []).map(doc => ({ ...doc, lower: doc.x.toLowerCase() }));
+  const maxResults = 200;
+
+  const escapeHTML = (s) => s.replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
+  const escapeRegExp = (s) => s.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+  // A short excerpt around the first match, with every term highlighted
+  const snippet = (text, terms) => {
+    const at = Math.max(0, text.toLowerCase().indexOf(terms[0]) - 60);
+    let excerpt = text.slice(at, at + 240);
+    if (at > 0) excerpt = '…' + excerpt;
+    if (at + 240 < text.length) excerpt += '…';
+    // Matched in the raw text and escaped piece by piece, so a term never
+    // matches inside an entity such as &amp;
+    const pattern = new RegExp(`(${terms.map(escapeRegExp).join('|')})`, 'gi');
+    return excerpt.split(pattern)
+      .map((part, i) => i % 2 ? `<mark>${escapeHTML(part)}</mark>` : escapeHTML(part))
+      .join('');
+  };
+
+  const search = () => {
+    const terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    list.innerHTML = '';
+    if (terms.length === 0) {
+      status.textContent = `${index.length} notes`;
+      return;
+    }
+
+    const hits = [];
+    // Newest first
+    for (let i = index.length - 1; i >= 0 && hits.length < maxResults; i--) {
+      if (terms.every(t => index[i].lower.includes(t))) hits.push(index[i]);
+    }
+    status.textContent = hits.length >= maxResults ? `First ${maxResults} matches` : `${hits.length} matches`;
+
+    for (const doc of hits) {
+      const li = document.createElement('li');
+      li.innerHTML = `<div class="meta"><a href="${doc.u}">${doc.d} ${doc.t}</a></div><div>${snippet(doc.x, terms)}</div>`;
+      list.appendChild(li);
+    }
+  };
+
+  input.addEventListener('input', search);
+  const q = new URLSearchParams(location.search).get('q');
+  if (q) input.value = q;
+  search();
+})();
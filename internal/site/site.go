@@ -0,0 +1,561 @@
+// Package site builds a read-only static HTML site from a notebook: day,
+// month and year pages, tag pages and a client-side search, with the
+// attachments the notes link to copied alongside
+package site
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"t-log/internal/attachment"
+	"t-log/internal/fsutil"
+	"t-log/internal/note"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markerFile identifies a folder written by Build, which may be cleared on
+// the next build. Folders without it are never emptied.
+const markerFile = ".t-log-site"
+
+// recentDays is how many days the home page shows in full
+const recentDays = 7
+
+var (
+	//go:embed templates/*.tmpl
+	templateFS embed.FS
+	//go:embed assets/*
+	assetFS embed.FS
+)
+
+// Options controls a build
+type Options struct {
+	Title    string // Site title; defaults to the notebook name
+	Notebook string
+	RootPath string
+	Layout   note.Layout
+	OutDir   string
+}
+
+// Report summarises a build
+type Report struct {
+	OutDir      string `json:"outDir"`
+	Index       string `json:"index"` // Path of the home page
+	Days        int    `json:"days"`
+	Entries     int    `json:"entries"`
+	Tags        int    `json:"tags"`
+	Attachments int    `json:"attachments"` // Files copied
+	Pages       int    `json:"pages"`
+}
+
+// entry is one rendered note
+type entry struct {
+	ID   string // Anchor on the day page
+	Time string
+	HTML template.HTML
+	Tags []*tag
+}
+
+type day struct {
+	Date       string
+	Label      string // Monday, January 6
+	URL        string // Relative to the site root
+	Entries    []*entry
+	Prev, Next *day
+}
+
+type month struct {
+	Key   string // 2025-01
+	Label string // January 2025
+	URL   string
+	Days  []*day
+	Count int // Entries
+}
+
+type year struct {
+	Year   string
+	URL    string
+	Months []*month
+	Count  int
+}
+
+type tag struct {
+	Name    string
+	URL     string
+	Entries []tagEntry
+}
+
+// tagEntry is an entry listed on a tag page
+type tagEntry struct {
+	Day   *day
+	Entry *entry
+}
+
+// page is what every template receives
+type page struct {
+	Site      string // Site title
+	Notebook  string
+	Title     string // Page title
+	Root      string // Relative path from the page to the site root ("", "../", "../../")
+	Generated time.Time
+
+	Years  []*year
+	Tags   []*tag
+	Recent []*day
+	Year   *year
+	Month  *month
+	Day    *day
+	Tag    *tag
+}
+
+// section is a day rendered inside a page, for the "day-section" and
+// "entries" templates
+type section struct {
+	Day     *day
+	Entries []*entry
+	Root    string
+}
+
+// templateFuncs are available to the site templates
+var templateFuncs = template.FuncMap{
+	"section": func(d *day, root string) section {
+		return section{Day: d, Entries: d.Entries, Root: root}
+	},
+}
+
+// searchDoc is one entry of the search index
+type searchDoc struct {
+	Date string `json:"d"`
+	Time string `json:"t"`
+	URL  string `json:"u"`
+	Text string `json:"x"`
+}
+
+// builder holds the state of one build
+type builder struct {
+	opts   Options
+	md     goldmark.Markdown
+	tmpl   map[string]*template.Template
+	tags   map[string]*tag
+	copied map[string]string // Source file -> site path
+	report Report
+}
+
+// Build writes the site for opts.RootPath to opts.OutDir
+func Build(opts Options) (*Report, error) {
+	if opts.Title == "" {
+		opts.Title = "t-log"
+		if opts.Notebook != "" {
+			opts.Title = opts.Notebook
+		}
+	}
+	if err := prepareOutDir(opts.RootPath, opts.OutDir); err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		opts: opts,
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithRendererOptions(html.WithHardWraps(), html.WithUnsafe()),
+		),
+		tags:   map[string]*tag{},
+		copied: map[string]string{},
+		report: Report{OutDir: opts.OutDir, Index: filepath.Join(opts.OutDir, "index.html")},
+	}
+	var err error
+	if b.tmpl, err = loadTemplates(); err != nil {
+		return nil, err
+	}
+
+	days, index, err := b.readDays()
+	if err != nil {
+		return nil, err
+	}
+	years := groupDays(days)
+	tags := b.sortedTags()
+
+	base := page{Site: opts.Title, Notebook: opts.Notebook, Generated: time.Now(), Years: years, Tags: tags}
+
+	recent := days
+	if len(recent) > recentDays {
+		recent = recent[len(recent)-recentDays:]
+	}
+	home := base
+	home.Title = opts.Title
+	home.Recent = reversed(recent)
+	if err := b.write("index.html", "index", home); err != nil {
+		return nil, err
+	}
+
+	search := base
+	search.Title = "Search"
+	if err := b.write("search.html", "search", search); err != nil {
+		return nil, err
+	}
+
+	for _, y := range years {
+		p := base
+		p.Title, p.Root, p.Year = y.Year, "../", y
+		if err := b.write(y.URL, "year", p); err != nil {
+			return nil, err
+		}
+		for _, m := range y.Months {
+			p := base
+			p.Title, p.Root, p.Month = m.Label, "../../", m
+			if err := b.write(m.URL, "month", p); err != nil {
+				return nil, err
+			}
+			for _, d := range m.Days {
+				p := base
+				p.Title, p.Root, p.Day = d.Date, "../../", d
+				if err := b.write(d.URL, "day", p); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	tagsPage := base
+	tagsPage.Title, tagsPage.Root = "Tags", "../"
+	if err := b.write("tags/index.html", "tags", tagsPage); err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		p := base
+		p.Title, p.Root, p.Tag = "#"+t.Name, "../", t
+		if err := b.write(t.URL, "tag", p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.writeSearchIndex(index); err != nil {
+		return nil, err
+	}
+	if err := b.copyAssets(); err != nil {
+		return nil, err
+	}
+
+	b.report.Days = len(days)
+	b.report.Tags = len(tags)
+	b.report.Attachments = len(b.copied)
+	return &b.report, nil
+}
+
+// prepareOutDir creates outDir, or empties it when an earlier build wrote
+// it. Folders holding anything else are refused, as are folders overlapping
+// the notebook.
+func prepareOutDir(rootPath, outDir string) error {
+	if outDir == "" {
+		return errors.New("no output folder")
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return err
+	}
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	if fsutil.Within(absRoot, absOut) || fsutil.Within(absOut, absRoot) {
+		return fmt.Errorf("output folder %s must be outside the notebook folder %s", outDir, rootPath)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output folder: %w", err)
+		}
+		return os.WriteFile(filepath.Join(outDir, markerFile), nil, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read output folder: %w", err)
+	}
+	if len(entries) == 0 {
+		return os.WriteFile(filepath.Join(outDir, markerFile), nil, 0644)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, markerFile)); err != nil {
+		return fmt.Errorf("output folder %s is not empty and was not created by t-log", outDir)
+	}
+
+	for _, e := range entries {
+		if e.Name() == markerFile {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(outDir, e.Name())); err != nil {
+			return fmt.Errorf("failed to clear output folder: %w", err)
+		}
+	}
+	return nil
+}
+
+// readDays renders every daily note, oldest first, and collects the tags
+// and the search index on the way
+func (b *builder) readDays() ([]*day, []searchDoc, error) {
+	dates, err := note.ListNoteDates(b.opts.RootPath, b.opts.Layout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	sort.Strings(dates)
+
+	var days []*day
+	index := []searchDoc{}
+	for _, date := range dates {
+		t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			continue
+		}
+		notePath := b.opts.Layout.DailyFile(b.opts.RootPath, t)
+		data, err := os.ReadFile(notePath)
+		if os.IsNotExist(err) {
+			continue // Removed since it was listed
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", notePath, err)
+		}
+
+		d := &day{
+			Date:  date,
+			Label: t.Format("Monday, January 2"),
+			URL:   path.Join(t.Format("2006"), t.Format("01"), date+".html"),
+		}
+		for n, ne := range note.ParseEntries(date, string(data)) {
+			e := &entry{ID: fmt.Sprintf("e%d", n+1), Time: ne.Timestamp}
+			if e.HTML, err = b.render(ne.Content, notePath); err != nil {
+				return nil, nil, err
+			}
+			for _, name := range note.Tags(ne.Content) {
+				tg := b.tag(name)
+				tg.Entries = append(tg.Entries, tagEntry{Day: d, Entry: e})
+				e.Tags = append(e.Tags, tg)
+			}
+			d.Entries = append(d.Entries, e)
+			index = append(index, searchDoc{Date: date, Time: ne.Timestamp, URL: d.URL + "#" + e.ID, Text: ne.Content})
+		}
+		if len(d.Entries) == 0 {
+			continue
+		}
+		if len(days) > 0 {
+			prev := days[len(days)-1]
+			d.Prev, prev.Next = prev, d
+		}
+		days = append(days, d)
+		b.report.Entries += len(d.Entries)
+	}
+	return days, index, nil
+}
+
+func (b *builder) tag(name string) *tag {
+	if t, ok := b.tags[name]; ok {
+		return t
+	}
+	file := strings.NewReplacer("/", "~", `\`, "~").Replace(name) + ".html"
+	t := &tag{Name: name, URL: "tags/" + file}
+	b.tags[name] = t
+	return t
+}
+
+// sortedTags lists tags by use, then name
+func (b *builder) sortedTags() []*tag {
+	tags := make([]*tag, 0, len(b.tags))
+	for _, t := range b.tags {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if len(tags[i].Entries) != len(tags[j].Entries) {
+			return len(tags[i].Entries) > len(tags[j].Entries)
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags
+}
+
+// groupDays builds the year and month pages, newest first
+func groupDays(days []*day) []*year {
+	var years []*year
+	for i := len(days) - 1; i >= 0; i-- {
+		d := days[i]
+		y, m := d.Date[:4], d.Date[:7]
+		if len(years) == 0 || years[len(years)-1].Year != y {
+			years = append(years, &year{Year: y, URL: y + "/index.html"})
+		}
+		yr := years[len(years)-1]
+		if len(yr.Months) == 0 || yr.Months[len(yr.Months)-1].Key != m {
+			t, _ := time.Parse("2006-01", m)
+			yr.Months = append(yr.Months, &month{Key: m, Label: t.Format("January 2006"), URL: path.Join(y, m[5:], "index.html")})
+		}
+		mo := yr.Months[len(yr.Months)-1]
+		mo.Days = append(mo.Days, d)
+		mo.Count += len(d.Entries)
+		yr.Count += len(d.Entries)
+	}
+	return years
+}
+
+func reversed(days []*day) []*day {
+	out := make([]*day, len(days))
+	for i, d := range days {
+		out[len(days)-1-i] = d
+	}
+	return out
+}
+
+// linkAttrRegex matches src and href attributes in rendered notes
+var linkAttrRegex = regexp.MustCompile(`(\s(?:src|href)=")([^"]+)(")`)
+
+// render converts a note to HTML, copying the attachments it links to.
+// Their links start with rootPlaceholder, as the note appears on pages at
+// different depths.
+func (b *builder) render(content, notePath string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := b.md.Convert([]byte(content), &buf); err != nil {
+		return "", fmt.Errorf("failed to render note: %w", err)
+	}
+	out := linkAttrRegex.ReplaceAllStringFunc(buf.String(), func(match string) string {
+		m := linkAttrRegex.FindStringSubmatch(match)
+		target := strings.ReplaceAll(m[2], "&amp;", "&")
+		src := attachment.ResolveLink(b.opts.RootPath, notePath, target)
+		if src == "" {
+			return match
+		}
+		sitePath, err := b.copyAttachment(src)
+		if err != nil {
+			fmt.Printf("Error copying attachment %s: %v\n", src, err)
+			return match
+		}
+		return m[1] + rootPlaceholder + escapePath(sitePath) + m[3]
+	})
+	return template.HTML(out), nil
+}
+
+// rootPlaceholder stands for the page's path to the site root in rendered
+// notes; write replaces it with page.Root
+const rootPlaceholder = "\x00root\x00"
+
+// copyAttachment copies an attachment to attachments/<path in the notebook>
+// and returns its site path
+func (b *builder) copyAttachment(src string) (string, error) {
+	if p, ok := b.copied[src]; ok {
+		return p, nil
+	}
+	rel, err := filepath.Rel(b.opts.RootPath, src)
+	if err != nil {
+		return "", err
+	}
+	sitePath := path.Join("attachments", filepath.ToSlash(rel))
+	if err := copyFile(src, filepath.Join(b.opts.OutDir, filepath.FromSlash(sitePath))); err != nil {
+		return "", err
+	}
+	b.copied[src] = sitePath
+	return sitePath, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// escapePath URL-escapes each segment of a slash separated path
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// loadTemplates parses each page template together with the shared layout
+func loadTemplates() (map[string]*template.Template, error) {
+	base, err := template.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/base.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("invalid site template: %w", err)
+	}
+	pages := map[string]*template.Template{}
+	for _, name := range []string{"index", "search", "year", "month", "day", "tags", "tag"} {
+		t, err := template.Must(base.Clone()).ParseFS(templateFS, "templates/"+name+".tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("invalid site template %s: %w", name, err)
+		}
+		pages[name] = t
+	}
+	return pages, nil
+}
+
+// write renders a page to rel (slash separated, relative to the site root)
+func (b *builder) write(rel, name string, p page) error {
+	var buf bytes.Buffer
+	if err := b.tmpl[name].ExecuteTemplate(&buf, "base", p); err != nil {
+		return fmt.Errorf("failed to render %s: %w", rel, err)
+	}
+	out := bytes.ReplaceAll(buf.Bytes(), []byte(rootPlaceholder), []byte(p.Root))
+
+	dst := filepath.Join(b.opts.OutDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.WriteFile(dst, out, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	b.report.Pages++
+	return nil
+}
+
+// writeSearchIndex writes the index as a script rather than JSON, so the
+// search works when the site is opened straight from disk (file:// pages
+// can't fetch)
+func (b *builder) writeSearchIndex(index []searchDoc) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	script := append([]byte("window.SEARCH_INDEX = "), data...)
+	script = append(script, ";\n"...)
+	return os.WriteFile(filepath.Join(b.opts.OutDir, "search-index.js"), script, 0644)
+}
+
+func (b *builder) copyAssets() error {
+	entries, err := assetFS.ReadDir("assets")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		data, err := assetFS.ReadFile("assets/" + e.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(b.opts.OutDir, e.Name()), data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"t-log/internal/note"
+)
+
+func TestPrepareOutDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, root, out string) string // Returns the output folder to use
+		wantErr string
+		kept    string // File in the output folder that must survive
+	}{
+		{
+			name:  "new folder",
+			setup: func(t *testing.T, root, out string) string { return filepath.Join(out, "site") },
+		},
+		{
+			name:  "empty folder",
+			setup: func(t *testing.T, root, out string) string { return out },
+		},
+		{
+			name: "foreign files",
+			setup: func(t *testing.T, root, out string) string {
+				writeFile(t, filepath.Join(out, "thesis.docx"), "mine")
+				return out
+			},
+			wantErr: "not empty",
+			kept:    "thesis.docx",
+		},
+		{
+			name: "earlier build",
+			setup: func(t *testing.T, root, out string) string {
+				writeFile(t, filepath.Join(out, markerFile), "")
+				writeFile(t, filepath.Join(out, "2024", "old.html"), "stale")
+				return out
+			},
+		},
+		{
+			name:    "inside the notebook",
+			setup:   func(t *testing.T, root, out string) string { return filepath.Join(root, "site") },
+			wantErr: "outside the notebook",
+		},
+		{
+			name:    "the notebook itself",
+			setup:   func(t *testing.T, root, out string) string { return root },
+			wantErr: "outside the notebook",
+		},
+		{
+			name:    "containing the notebook",
+			setup:   func(t *testing.T, root, out string) string { return filepath.Dir(root) },
+			wantErr: "outside the notebook",
+		},
+		{
+			// Shares a name prefix with the notebook but is a sibling
+			name:  "sibling with a similar name",
+			setup: func(t *testing.T, root, out string) string { return root + "-site" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "notes")
+			writeFile(t, filepath.Join(root, "2025", "06", "2025-06-30.md"), "- [09:00] hi\n")
+			outDir := tt.setup(t, root, t.TempDir())
+
+			err := prepareOutDir(root, outDir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if tt.kept != "" {
+					if _, err := os.Stat(filepath.Join(outDir, tt.kept)); err != nil {
+						t.Errorf("refused folder was changed: %v", err)
+					}
+				}
+				if _, err := os.Stat(filepath.Join(root, "2025", "06", "2025-06-30.md")); err != nil {
+					t.Errorf("note was touched: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(outDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Name() != markerFile {
+				t.Errorf("output folder holds %v, want only the marker", entries)
+			}
+		})
+	}
+}
+
+func TestBuildAttachmentLinks(t *testing.T) {
+	root := t.TempDir()
+	day := time.Date(2025, 6, 30, 9, 0, 0, 0, time.Local)
+	attachDir := filepath.Join(root, "2025", "06", "Attachment")
+	writeFile(t, filepath.Join(attachDir, "shot.png"), "png")
+	writeFile(t, filepath.Join(attachDir, "plan.pdf"), "pdf")
+
+	content := "![shot](Attachment/shot.png) [plan](/attachments/2025/06/Attachment/plan.pdf) [site](https://example.com/) #pics"
+	if _, err := note.SaveNoteAt(root, note.LayoutMonthly, content, day); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(t.TempDir(), "site")
+	report, err := Build(Options{RootPath: root, Layout: note.LayoutMonthly, OutDir: outDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Entries != 1 || report.Attachments != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	for _, name := range []string{"shot.png", "plan.pdf"} {
+		if _, err := os.Stat(filepath.Join(outDir, "attachments", "2025", "06", "Attachment", name)); err != nil {
+			t.Errorf("%s not copied: %v", name, err)
+		}
+	}
+
+	// The same note is shown on pages at every depth
+	pages := []struct {
+		page, root string
+	}{
+		{"index.html", ""},
+		{"tags/pics.html", "../"},
+		{"2025/06/index.html", "../../"},
+		{"2025/06/2025-06-30.html", "../../"},
+	}
+	for _, p := range pages {
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(p.page)))
+		if err != nil {
+			t.Errorf("%s: %v", p.page, err)
+			continue
+		}
+		html := string(data)
+		for _, want := range []string{
+			`src="` + p.root + `attachments/2025/06/Attachment/shot.png"`,
+			`href="` + p.root + `attachments/2025/06/Attachment/plan.pdf"`,
+			`href="https://example.com/"`,
+		} {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s: missing %s", p.page, want)
+			}
+		}
+		if strings.Contains(html, rootPlaceholder) {
+			t.Errorf("%s: placeholder left in the page", p.page)
+		}
+	}
+
+	// Building again replaces the earlier site
+	if _, err := Build(Options{RootPath: root, Layout: note.LayoutMonthly, OutDir: outDir}); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
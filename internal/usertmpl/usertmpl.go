@@ -0,0 +1,35 @@
+// Package usertmpl loads templates the user can override by dropping an
+// edited copy into a folder of the config directory
+package usertmpl
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source returns the template called name: dir/name when it exists (and dir
+// is set), otherwise templates/name from builtin
+func Source(builtin fs.FS, dir, name string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+	}
+	data, err := fs.ReadFile(builtin, "templates/"+name)
+	if err != nil {
+		return "", fmt.Errorf("no built-in template %s", name)
+	}
+	return string(data), nil
+}
+
+// Indent continues a multi-line note inside its markdown list item
+func Indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n  ")
+}
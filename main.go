@@ -5,8 +5,10 @@ import (
 	"embed"
 
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"t-log/internal/attachment"
 	"t-log/internal/config"
 
 	"github.com/wailsapp/wails/v2"
@@ -21,8 +23,37 @@ import (
 var assets embed.FS
 
 func main() {
+	args := parseArgs(os.Args[1:], "")
+
+	// The config file is resolved once (--config, $TLOG_CONFIG, then the per-user
+	// config dir). Everything reads it through the shared config service, so a
+	// change made in Settings or on disk reaches every subsystem.
+	configPath, err := config.ResolveConfigPath(args.ConfigPath)
+	if err != nil {
+		println("Error resolving config path:", err.Error())
+		configPath = config.ConfigFileName
+	}
+	configSvc := config.NewService(configPath)
+
+	// t-log --export and --site write their output and exit without opening a window
+	if args.Export != "" {
+		if err := runExport(configSvc.Get(), args, filepath.Dir(configPath)); err != nil {
+			println("Error exporting notes:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	if args.Site != "" {
+		if err := runSite(configSvc.Get(), args); err != nil {
+			println("Error building site:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(configSvc)
+	app.args = args
 
 	// Create System Tray Menu
 	trayMenu := menu.NewMenu()
@@ -43,32 +74,30 @@ func main() {
 	// Create application with options
 
 	// Custom asset handler to serve attachments
-	// Intercepts /attachments/ and serves from Config.RootPath
-	// Note: We need to load config briefly here or rely on the fact that
-	// main.go initializes the App which loads config. But AssetServer needs
-	// the handler BEFORE App.startup is called.
-	// Ideally, we load config first.
-
-	cfg, _ := config.LoadConfig() // If error, we might default or fail. Using ignore for now as App.startup reloads it.
-	// But we NEED the path for the handler closure.
-	// If LoadConfig fails here (e.g. first run), RootPath might be "QuickNotes" relative or empty.
-	// Let's rely on the same logic as App.startup to get a usable path if possible.
-	if cfg == nil {
-		cfg = config.DefaultConfig() // Fallback
-	}
-
+	// Intercepts /attachments/ and serves from the notebook roots.
+	// The AssetServer needs the handler before App.startup is called, so it
+	// reads RootPath from the config service on every request instead of
+	// capturing it once; a changed RootPath takes effect immediately.
 	assetHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasPrefix(r.URL.Path, "/attachments/") {
+		// Relative links (Attachment/x.png) rendered from the root page arrive as
+		// /Attachment/x.png or /YYYY/MM/Attachment/x.png
+		if strings.HasPrefix(r.URL.Path, "/attachments/") || strings.Contains(r.URL.Path, "/Attachment/") {
 			// Expected path: /attachments/YYYY/MM/Attachment/file.ext
 			// Physical path: {RootPath}/YYYY/MM/Attachment/file.ext
-			relativePath := strings.TrimPrefix(r.URL.Path, "/attachments/")
+			// Security check: ResolveWebPath refuses paths that escape RootPath
+			fullPath, err := resolveAttachment(configSvc.Get(), r.URL.EscapedPath())
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
 
-			// Security check: prevent directory traversal
-			// Join cleans the path, but we should ensure it's within RootPath
-			fullPath := filepath.Join(cfg.RootPath, relativePath)
+			// The history panel asks for ?thumb=1 to get the small preview if one exists
+			if r.URL.Query().Get("thumb") != "" {
+				if thumb := attachment.ThumbnailFile(fullPath); thumb != "" {
+					fullPath = thumb
+				}
+			}
 
-			// Basic check to ensure we are serving files, not arbitrary system paths
-			// (filepath.Join handles .. cleaning, but we trust RootPath is safe)
 			http.ServeFile(w, r, fullPath)
 			return
 		}
@@ -82,7 +111,7 @@ func main() {
 		http.NotFound(w, r)
 	})
 
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:       "Quick Capture",
 		Width:       400,
 		Height:      500,
@@ -94,6 +123,11 @@ func main() {
 			Handler: assetHandler,
 		},
 		BackgroundColour: &options.RGBA{R: 0, G: 0, B: 0, A: 0},
+		DragAndDrop: &options.DragAndDrop{
+			// Dropped files are copied into the attachment folder by App.startup's OnFileDrop
+			EnableFileDrop:     true,
+			DisableWebViewDrop: true,
+		},
 		SingleInstanceLock: &options.SingleInstanceLock{
 			UniqueId: "t-log-quick-capture-single-instance",
 			OnSecondInstanceLaunch: func(secondInstanceData options.SecondInstanceData) {
@@ -115,11 +149,18 @@ func main() {
 				// the user can interact with it.
 				// If we want consistent behavior:
 				// runtime.WindowSetAlwaysOnTop(app.ctx, false) // after a delay
+
+				// `t-log --attach file` while running: hand the files to this instance
+				args := parseArgs(secondInstanceData.Args, secondInstanceData.WorkingDirectory)
+				if len(args.Attach) > 0 {
+					app.attachAndNotify(args.Attach)
+				}
 			},
 		},
 		OnStartup: func(ctx context.Context) {
 			app.startup(ctx)
 		},
+		OnDomReady: app.domReady,
 		OnShutdown: app.shutdown,
 		Bind: []interface{}{
 			app,
@@ -149,3 +190,28 @@ func main() {
 		println("Error:", err.Error())
 	}
 }
+
+// resolveAttachment finds the file behind an attachment URL. Links do not
+// say which notebook they belong to, so the active notebook is tried first,
+// then the others.
+func resolveAttachment(cfg *config.AppConfig, webPath string) (string, error) {
+	active := cfg.Active()
+	fullPath, err := attachment.ResolveWebPath(active.RootPath, webPath)
+	if err == nil {
+		if _, statErr := os.Stat(fullPath); statErr == nil {
+			return fullPath, nil
+		}
+	}
+
+	for _, nb := range cfg.AllNotebooks() {
+		if nb.Name == active.Name {
+			continue
+		}
+		if p, err := attachment.ResolveWebPath(nb.RootPath, webPath); err == nil {
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			}
+		}
+	}
+	return fullPath, err
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// NotesChange is sent as "notes:changed" when daily files change on disk,
+// whether edited in an external editor or saved by the app
+type NotesChange struct {
+	Notebook string   `json:"notebook"`
+	Dates    []string `json:"dates"` // YYYY-MM-DD of the files that changed
+}
+
+// watchNotes (re)starts a watcher on the root of every notebook. A root that
+// is missing is created; one that can't be watched (an offline share) is
+// skipped until the next config change.
+func (a *App) watchNotes(cfg *config.AppConfig) {
+	ctx, cancel := context.WithCancel(a.ctx)
+
+	a.mu.Lock()
+	if a.stopNotesWatch != nil {
+		a.stopNotesWatch()
+	}
+	a.stopNotesWatch = cancel
+	a.mu.Unlock()
+
+	watched := map[string]bool{}
+	for _, nb := range cfg.AllNotebooks() {
+		if watched[nb.RootPath] {
+			continue
+		}
+		watched[nb.RootPath] = true
+
+		if err := os.MkdirAll(nb.RootPath, 0755); err != nil {
+			fmt.Printf("Error watching notebook %s: %v\n", nb.Name, err)
+			continue
+		}
+		name, root := nb.Name, nb.RootPath
+		if err := note.WatchNotes(ctx, root, func(dates []string) {
+			a.invalidateNotes(root, dates)
+			a.notesChanged(name, dates)
+		}); err != nil {
+			fmt.Printf("Error watching notebook %s: %v\n", nb.Name, err)
+		}
+	}
+}
+
+// notesChanged tells the frontend which days to refresh
+func (a *App) notesChanged(notebook string, dates []string) {
+	runtime.EventsEmit(a.ctx, "notes:changed", NotesChange{Notebook: notebook, Dates: dates})
+}
+
+// notebookRootsChanged reports whether any notebook's folder was added, removed or moved
+func notebookRootsChanged(old, cfg *config.AppConfig) bool {
+	oldNbs, newNbs := old.AllNotebooks(), cfg.AllNotebooks()
+	if len(oldNbs) != len(newNbs) {
+		return true
+	}
+	for i := range oldNbs {
+		if oldNbs[i].Name != newNbs[i].Name || oldNbs[i].RootPath != newNbs[i].RootPath {
+			return true
+		}
+	}
+	return false
+}
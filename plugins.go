@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"t-log/internal/command"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// loadPlugins (re)discovers plugins and registers their commands
+func (a *App) loadPlugins() {
+	if err := a.plugins.Load(); err != nil {
+		fmt.Printf("Error loading plugins: %v\n", err)
+		return
+	}
+
+	a.pluginCmds.replace(a.cmdRegistry, a.plugins.Commands(), func(cmd command.Command) command.Handler {
+		id := cmd.ID
+		return func(args command.Args) error {
+			return a.runPluginCommand(id, args)
+		}
+	})
+	if n := len(a.plugins.Plugins()); n > 0 {
+		fmt.Printf("Loaded %d plugins from %s\n", n, a.plugins.Dir())
+	}
+}
+
+// runPluginCommand runs a plugin command and carries out what its response asks for
+func (a *App) runPluginCommand(id string, args command.Args) error {
+	resp, err := a.plugins.RunCommand(id, args)
+	if err != nil {
+		return err
+	}
+
+	if resp.Insert != "" {
+		runtime.EventsEmit(a.ctx, "editor:insert", resp.Insert)
+	}
+	if resp.Append != "" {
+		if _, err := a.SaveNote(resp.Append); err != nil {
+			return err
+		}
+	}
+	if resp.OpenURL != "" {
+		runtime.BrowserOpenURL(a.ctx, resp.OpenURL)
+	}
+	if resp.Message != "" {
+		runtime.EventsEmit(a.ctx, "plugin:message", resp.Message)
+	}
+	return nil
+}
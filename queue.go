@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"t-log/internal/config"
+	"t-log/internal/note"
+	"t-log/internal/plugin"
+	"t-log/internal/queue"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// queueRetry is how often notes waiting for unavailable storage are retried
+const queueRetry = 30 * time.Second
+
+// startQueue opens the capture queue and retries delivery in the background
+// until ctx is done
+func (a *App) startQueue(ctx context.Context) {
+	q, err := queue.Open(filepath.Join(a.configDir(), queue.DirName))
+	if err != nil {
+		// Saving still works; failed saves just aren't kept
+		fmt.Printf("Error opening capture queue: %v\n", err)
+		return
+	}
+	a.queue = q
+
+	go func() {
+		ticker := time.NewTicker(queueRetry)
+		defer ticker.Stop()
+		for {
+			a.flushQueue()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// enqueue accepts a note whose notebook could not be written
+func (a *App) enqueue(nb config.Notebook, content string, now time.Time) (note.SavedEntry, error) {
+	item, err := a.queue.Add(queue.Item{
+		Notebook:  nb.Name,
+		RootPath:  nb.RootPath,
+		Layout:    nb.Layout,
+		Content:   content,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return note.SavedEntry{}, err
+	}
+	a.emitQueueChanged()
+	return note.SavedEntry{ID: item.ID, Content: content, SavedAt: now, Queued: true}, nil
+}
+
+// flushQueue tries to write every pending note to its daily file, filed
+// under the time it was captured
+func (a *App) flushQueue() error {
+	if a.queue == nil || a.queue.Len() == 0 {
+		return nil
+	}
+
+	delivered, err := a.queue.Flush(func(item queue.Item) error {
+		entry, err := note.SaveNoteAt(item.RootPath, note.Layout(item.Layout), item.Content, item.CreatedAt)
+		if errors.Is(err, note.ErrNotWritten) {
+			return err
+		}
+		if err != nil {
+			// Part or all of it may be in the file; retrying could duplicate it
+			fmt.Printf("Error delivering queued note, not retrying: %v\n", err)
+			return nil
+		}
+		a.invalidateNotes(item.RootPath, []string{item.CreatedAt.Format("2006-01-02")})
+		if a.plugins != nil {
+			a.plugins.PostSave(plugin.Note{
+				Content:  item.Content,
+				Notebook: item.Notebook,
+				Date:     item.CreatedAt.Format("2006-01-02"),
+				Time:     item.CreatedAt.Format("15:04"),
+				File:     entry.File,
+			})
+		}
+		return nil
+	})
+	if delivered > 0 {
+		fmt.Printf("Delivered %d queued notes\n", delivered)
+		a.emitQueueChanged()
+	}
+	return err
+}
+
+func (a *App) emitQueueChanged() {
+	runtime.EventsEmit(a.ctx, "queue:changed", a.GetPendingCount())
+}
+
+// GetPendingCount returns how many captured notes are waiting for their storage
+func (a *App) GetPendingCount() int {
+	if a.queue == nil {
+		return 0
+	}
+	return a.queue.Len()
+}
+
+// GetPendingNotes returns the notes waiting for their storage, oldest first
+func (a *App) GetPendingNotes() []queue.Item {
+	if a.queue == nil {
+		return []queue.Item{}
+	}
+	items, err := a.queue.Items()
+	if err != nil || items == nil {
+		return []queue.Item{}
+	}
+	return items
+}
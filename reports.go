@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/note"
+	"t-log/internal/report"
+)
+
+// registerReportCommands registers the palette commands for weekly and
+// monthly reports
+func (a *App) registerReportCommands() {
+	periods := []string{string(report.Weekly), string(report.Monthly)}
+
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:report",
+		Title:       "Generate Report...",
+		Description: "Write the week's or month's report next to the notes and open it",
+		Usage:       "report [week|month] [date]",
+		Aliases:     []string{"weekly", "monthly", "status"},
+		Params: []command.Param{
+			{Name: "period", Type: command.ParamEnum, Prompt: "Week or month...", Options: periods, Default: string(report.Weekly)},
+			{Name: "date", Type: command.ParamDate, Prompt: "Any day in the period (YYYY-MM-DD)...", Default: "today"},
+		},
+	}, func(args command.Args) error {
+		path, err := a.GenerateReport(args.Get("period"), args.Get("date"))
+		if err != nil {
+			return err
+		}
+		return note.OpenFile(path)
+	})
+
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:edit-report-template",
+		Title:       "Edit Report Template...",
+		Description: "Open the weekly or monthly report template in the editor",
+		Usage:       "edit-report-template [week|month]",
+		Params: []command.Param{
+			{Name: "period", Type: command.ParamEnum, Prompt: "Week or month...", Options: periods, Default: string(report.Weekly)},
+		},
+	}, func(args command.Args) error {
+		path, err := a.reportTemplate(args.Get("period"))
+		if err != nil {
+			return err
+		}
+		return note.OpenFile(path)
+	})
+}
+
+// GenerateReport writes the report for the week or month containing date
+// (YYYY-MM-DD, empty for today) in the active notebook and returns its path
+func (a *App) GenerateReport(period, date string) (string, error) {
+	p, err := report.ParsePeriod(period)
+	if err != nil {
+		return "", err
+	}
+	t := time.Now()
+	if date != "" {
+		if t, err = time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			return "", fmt.Errorf("invalid date %q (use YYYY-MM-DD)", date)
+		}
+	}
+
+	nb := a.cfg().Active()
+	return report.Generate(p, t, report.Options{
+		Notebook:    nb.Name,
+		RootPath:    nb.RootPath,
+		Layout:      note.Layout(nb.Layout),
+		TemplateDir: filepath.Join(a.configDir(), report.TemplateDirName),
+	})
+}
+
+// reportTemplate returns the editable template for period, copying the
+// built-in one into the config dir the first time
+func (a *App) reportTemplate(period string) (string, error) {
+	p, err := report.ParsePeriod(period)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(a.configDir(), report.TemplateDirName)
+	path := filepath.Join(dir, p.TemplateName())
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template directory: %w", err)
+	}
+	if err := os.WriteFile(path, report.DefaultTemplate(p), 0644); err != nil {
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+	return path, nil
+}
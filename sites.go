@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"t-log/internal/command"
+	"t-log/internal/config"
+	"t-log/internal/note"
+	"t-log/internal/site"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// siteOptions describes a static site of notebook nb written to outDir
+func siteOptions(nb config.Notebook, outDir string) site.Options {
+	return site.Options{
+		Notebook: nb.Name,
+		RootPath: nb.RootPath,
+		Layout:   note.Layout(nb.Layout),
+		OutDir:   outDir,
+	}
+}
+
+// registerSiteCommands registers the palette command building a static site
+func (a *App) registerSiteCommands() {
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:build-site",
+		Title:       "Build Static Site...",
+		Description: "Write a browsable, searchable HTML copy of the notebook to a folder",
+		Usage:       "build-site [folder]",
+		Aliases:     []string{"publish", "html", "archive"},
+		Params: []command.Param{
+			{Name: "folder", Prompt: "Output folder (empty to choose)..."},
+		},
+	}, func(args command.Args) error {
+		report, err := a.BuildSite(args.Get("folder"))
+		if err != nil || report == nil {
+			return err
+		}
+		runtime.EventsEmit(a.ctx, "site:done", report)
+		return nil
+	})
+}
+
+// BuildSite writes a static site of the active notebook to outDir, asking
+// for a folder when outDir is empty. It returns nil when the dialog was
+// cancelled.
+func (a *App) BuildSite(outDir string) (*site.Report, error) {
+	if outDir == "" {
+		dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:                "Choose a folder for the site",
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to choose output folder: %w", err)
+		}
+		if dir == "" {
+			return nil, nil
+		}
+		outDir = dir
+	}
+	return site.Build(siteOptions(a.cfg().Active(), outDir))
+}
+
+// runSite handles "t-log --site <folder>": it builds the site without
+// starting the UI
+func runSite(cfg *config.AppConfig, args cliArgs) error {
+	nb, ok := cfg.FindNotebook(args.Notebook)
+	if args.Notebook == "" {
+		nb, ok = cfg.Active(), true
+	}
+	if !ok {
+		return fmt.Errorf("unknown notebook %q", args.Notebook)
+	}
+
+	report, err := site.Build(siteOptions(nb, args.Site))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d pages for %d days to %s\n", report.Pages, report.Days, report.OutDir)
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// slashPrefix marks the registry entries typed as "/name args" in the capture window
+const slashPrefix = "slash:"
+
+// NotesView is a set of days for the history panel, sent as "notes:show"
+type NotesView struct {
+	Title string           `json:"title"`
+	Mode  string           `json:"mode"` // "list" for one day, "export" for a range
+	Start string           `json:"start"`
+	End   string           `json:"end"`
+	Notes []note.DailyNote `json:"notes"`
+}
+
+// registerSlashCommands registers the history commands. All date math runs
+// here in local time; the frontend only renders the resulting NotesView.
+func (a *App) registerSlashCommands() {
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "today",
+		Title:       "Show Today",
+		Description: "Show today's notes",
+		Usage:       "/today",
+		Aliases:     []string{"list"},
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		return now, now
+	})
+
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "yesterday",
+		Title:       "Show Yesterday",
+		Description: "Show yesterday's notes",
+		Usage:       "/yesterday",
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		yesterday := now.AddDate(0, 0, -1)
+		return yesterday, yesterday
+	})
+
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "week",
+		Title:       "Show This Week",
+		Description: "Notes since Monday, for export",
+		Usage:       "/week",
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		return note.StartOfWeek(now), now
+	})
+
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "month",
+		Title:       "Show This Month",
+		Description: "Notes since the 1st, for export",
+		Usage:       "/month",
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		return note.StartOfMonth(now), now
+	})
+
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "days",
+		Title:       "Show Last Days...",
+		Description: "Notes of the last n days including today",
+		Usage:       "/days <n>",
+		Params: []command.Param{
+			{Name: "n", Type: command.ParamNumber, Prompt: "Number of days...", Default: "7"},
+		},
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		n, _ := strconv.Atoi(args.Get("n")) // Validated as a positive number
+		return now.AddDate(0, 0, -(n - 1)), now
+	})
+
+	a.registerSlash(command.Command{
+		ID:          slashPrefix + "since",
+		Title:       "Show Since Date...",
+		Description: "Notes from a date up to today",
+		Usage:       "/since <YYYY-MM-DD>",
+		Params: []command.Param{
+			{Name: "date", Type: command.ParamDate, Prompt: "Since date... (YYYY-MM-DD)", Required: true},
+		},
+	}, func(now time.Time, args command.Args) (time.Time, time.Time) {
+		since, _ := time.ParseInLocation("2006-01-02", args.Get("date"), now.Location())
+		return since, now
+	})
+}
+
+// registerSlash registers a command that shows the days between the range it returns
+func (a *App) registerSlash(cmd command.Command, span func(now time.Time, args command.Args) (time.Time, time.Time)) {
+	a.cmdRegistry.Register(cmd, func(args command.Args) error {
+		start, end := span(time.Now(), args)
+		if start.After(end) {
+			return fmt.Errorf("%s: start date is in the future", cmd.Usage)
+		}
+
+		view := NotesView{
+			Title: cmd.Title,
+			Mode:  "export",
+			Start: start.Format("2006-01-02"),
+			End:   end.Format("2006-01-02"),
+		}
+		if view.Start == view.End {
+			view.Mode = "list"
+		}
+
+		rootPath, layout := a.active()
+		notes, err := note.GetDailyNotes(rootPath, layout, view.Start, view.End)
+		if err != nil {
+			return err
+		}
+		view.Notes = notes
+		if view.Notes == nil {
+			view.Notes = []note.DailyNote{}
+		}
+
+		runtime.EventsEmit(a.ctx, "notes:show", view)
+		return nil
+	})
+}
+
+// GetSlashCommands returns the commands offered when typing "/" in the capture window
+func (a *App) GetSlashCommands() []command.Command {
+	cmds := []command.Command{}
+	for _, cmd := range a.cmdRegistry.GetCommands() {
+		if strings.HasPrefix(cmd.ID, slashPrefix) {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// RunSlashCommand runs a line such as "/days 14" or "/since 2025-01-01".
+// The result arrives as a "notes:show" event.
+func (a *App) RunSlashCommand(input string) error {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(input), "/"))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	name := strings.ToLower(fields[0])
+
+	for _, cmd := range a.GetSlashCommands() {
+		if cmd.ID == slashPrefix+name || slices.Contains(cmd.Aliases, name) {
+			return a.cmdRegistry.Execute(cmd.ID, fields[1:])
+		}
+	}
+	return fmt.Errorf("unknown command /%s", name)
+}
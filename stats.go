@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// StatsView asks the frontend to show the statistics of a range, sent as "stats:show"
+type StatsView struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// registerStatsCommands registers the palette command for the statistics view
+func (a *App) registerStatsCommands() {
+	a.cmdRegistry.Register(command.Command{
+		ID:          "cmd:stats",
+		Title:       "Show Statistics",
+		Description: "Show a heatmap of the last year with streaks, busiest hours and top tags",
+		Usage:       "stats [days]",
+		Aliases:     []string{"heatmap", "streak"},
+		Params: []command.Param{
+			{Name: "days", Type: command.ParamNumber, Prompt: "Number of days...", Default: "365"},
+		},
+	}, func(args command.Args) error {
+		days, _ := strconv.Atoi(args.Get("days")) // Validated as a positive number
+		now := time.Now()
+		runtime.EventsEmit(a.ctx, "stats:show", StatsView{
+			Start: now.AddDate(0, 0, 1-days).Format("2006-01-02"),
+			End:   now.Format("2006-01-02"),
+		})
+		return nil
+	})
+}
+
+// GetStats returns the statistics of the active notebook between start and
+// end (YYYY-MM-DD, inclusive); empty bounds cover the whole archive
+func (a *App) GetStats(start, end string) (*note.Stats, error) {
+	root, layout := a.active()
+	return a.statsCache(root, layout).Stats(start, end)
+}
+
+// statsCache returns the statistics cache for a notebook, creating it on first use
+func (a *App) statsCache(root string, layout note.Layout) *note.StatsCache {
+	index := a.dateIndex(root, layout)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := notebookKey{root, layout}
+	if a.stats == nil {
+		a.stats = map[notebookKey]*note.StatsCache{}
+	}
+	cache, ok := a.stats[key]
+	if !ok {
+		cache = note.NewStatsCache(index)
+		a.stats[key] = cache
+	}
+	return cache
+}
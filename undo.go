@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxUndo bounds how many saved notes the session remembers
+const maxUndo = 50
+
+// undoStack holds the notes saved this session, newest last
+type undoStack struct {
+	mu      sync.Mutex
+	entries []note.SavedEntry
+}
+
+func (s *undoStack) push(e note.SavedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	if len(s.entries) > maxUndo {
+		s.entries = s.entries[len(s.entries)-maxUndo:]
+	}
+}
+
+// pop removes and returns the newest entry
+func (s *undoStack) pop() (note.SavedEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return note.SavedEntry{}, false
+	}
+	e := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	return e, true
+}
+
+// clear forgets every entry
+func (s *undoStack) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+}
+
+// UndoLastNote removes the most recently saved note from its daily file and
+// returns its content so it can be edited and sent again. Only notes saved
+// within undo_minutes can be undone, and only if the file was not edited
+// around the note in the meantime. Repeated calls walk back further.
+func (a *App) UndoLastNote() (string, error) {
+	window := time.Duration(a.cfg().UndoMinutes) * time.Minute
+	if window == 0 {
+		return "", fmt.Errorf("undo is disabled (undo_minutes is 0)")
+	}
+
+	e, ok := a.undo.pop()
+	if !ok {
+		return "", fmt.Errorf("nothing to undo")
+	}
+	if time.Since(e.SavedAt) > window {
+		// Everything older is past the window too
+		a.undo.clear()
+		return "", fmt.Errorf("the last note was saved more than %d minutes ago", a.cfg().UndoMinutes)
+	}
+
+	if err := note.RemoveEntry(e); err != nil {
+		if errors.Is(err, note.ErrEntryChanged) {
+			return "", fmt.Errorf("cannot undo: %w; edit %s instead", err, e.File)
+		}
+		return "", err
+	}
+	a.invalidateFile(e.File, e.SavedAt.Format("2006-01-02"))
+
+	runtime.EventsEmit(a.ctx, "note:undone", e)
+	return e.Content, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"t-log/internal/command"
+	"t-log/internal/note"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// commandSet tracks a group of registry entries loaded from outside the
+// binary (commands.json, plugins) so a reload can replace the whole group,
+// dropping commands that no longer exist
+type commandSet struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// replace unregisters the previous group and registers cmds, each with the
+// handler returned for it
+func (s *commandSet) replace(r *command.CommandRegistry, cmds []command.Command, handler func(command.Command) command.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.ids {
+		r.Unregister(id)
+	}
+	s.ids = nil
+
+	for _, cmd := range cmds {
+		r.Register(cmd, handler(cmd))
+		s.ids = append(s.ids, cmd.ID)
+	}
+}
+
+// configDir is where config.json lives; user commands and usage data sit next to it
+func (a *App) configDir() string {
+	return filepath.Dir(a.configSvc.Path())
+}
+
+// loadUserCommands (re)registers the commands defined in commands.json or
+// commands.yaml. If the file is invalid the previous commands stay in place.
+func (a *App) loadUserCommands() {
+	cmds, path, err := command.LoadUserCommands(a.configDir())
+	if err != nil {
+		fmt.Printf("Error loading user commands: %v\n", err)
+		return
+	}
+
+	byID := make(map[string]command.UserCommand, len(cmds))
+	palette := make([]command.Command, len(cmds))
+	for i, uc := range cmds {
+		byID[uc.ID] = uc
+		palette[i] = uc.Command()
+	}
+	a.userCmds.replace(a.cmdRegistry, palette, func(cmd command.Command) command.Handler {
+		uc := byID[cmd.ID]
+		return func(args command.Args) error {
+			return a.runUserCommand(uc, args)
+		}
+	})
+	if path != "" {
+		fmt.Printf("Loaded %d user commands from %s\n", len(cmds), path)
+	}
+}
+
+// commandVars are the variables available to user command actions:
+// {date}, {time}, {file} (today's note), {notebook}, {root}, {selection},
+// {clipboard} and every parameter by name
+func (a *App) commandVars(args command.Args) map[string]string {
+	now := time.Now()
+	nb, _ := a.captureNotebook("")
+
+	vars := map[string]string{
+		"date":     now.Format("2006-01-02"),
+		"time":     now.Format("15:04"),
+		"file":     note.Layout(nb.Layout).DailyFile(nb.RootPath, now),
+		"notebook": nb.Name,
+		"root":     nb.RootPath,
+	}
+	if text, err := runtime.ClipboardGetText(a.ctx); err == nil {
+		vars["clipboard"] = text
+	}
+	for k, v := range args {
+		vars[k] = v
+	}
+	if _, ok := vars["selection"]; !ok {
+		vars["selection"] = ""
+	}
+	return vars
+}
+
+// runUserCommand performs a user command's actions in order, stopping at the first failure
+func (a *App) runUserCommand(uc command.UserCommand, args command.Args) error {
+	vars := a.commandVars(args)
+
+	for i, act := range uc.Actions {
+		var err error
+		switch act.Type {
+		case command.ActionInsert:
+			runtime.EventsEmit(a.ctx, "editor:insert", command.Expand(act.Text, vars))
+		case command.ActionAppend:
+			_, err = a.SaveNote(command.Expand(act.Text, vars))
+		case command.ActionOpenURL:
+			runtime.BrowserOpenURL(a.ctx, command.Expand(act.URL, escapeVars(vars)))
+		case command.ActionOpen:
+			err = note.OpenFile(command.Expand(act.Path, vars))
+		case command.ActionShell:
+			err = a.runShellAction(act, vars)
+		default:
+			err = fmt.Errorf("unknown action type %q", act.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: action %d (%s): %w", uc.ID, i+1, act.Type, err)
+		}
+	}
+	return nil
+}
+
+// escapeVars percent-encodes values substituted into a URL, so a page name
+// with spaces or "&" stays a single path segment or query value
+func escapeVars(vars map[string]string) map[string]string {
+	escaped := make(map[string]string, len(vars))
+	for k, v := range vars {
+		escaped[k] = url.PathEscape(v)
+	}
+	return escaped
+}
+
+// runShellAction starts the program in the background from the notebook root.
+// Arguments are passed directly, not through a shell, so a {selection}
+// containing quotes or semicolons cannot change what runs.
+func (a *App) runShellAction(act command.Action, vars map[string]string) error {
+	args := make([]string, len(act.Args))
+	for i, arg := range act.Args {
+		args[i] = command.Expand(arg, vars)
+	}
+
+	cmd := exec.Command(command.Expand(act.Command, vars), args...)
+	cmd.Dir = vars["root"]
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			fmt.Printf("Command %s failed: %v\n%s", act.Command, err, output.String())
+		}
+	}()
+	return nil
+}